@@ -20,6 +20,8 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"regexp"
+	"strings"
 
 	"github.com/spf13/cobra"
 
@@ -79,6 +81,89 @@ const (
 
 var disableCompDescriptions bool
 
+// redactedValue replaces sensitive values in completion debug output
+const redactedValue = "******"
+
+// sensitiveFlags lists the flags whose values must never appear in completion
+// debug output, which may be written to BASH_COMP_DEBUG_FILE
+var sensitiveFlags = map[string]bool{
+	"password":       true,
+	"token":          true,
+	"kube-token":     true,
+	"access-token":   true,
+	"identity-token": true,
+}
+
+// sensitiveShorthands maps the shorthands of sensitive flags to their names.
+// Other commands may use the same shorthand for a harmless flag, whose value
+// is then masked as well
+var sensitiveShorthands = map[string]string{
+	"p": "password",
+}
+
+// valueFlags maps the flags of the form key=value to the function redacting
+// the values whose key looks like it holds a secret
+var valueFlags = map[string]func(string) string{
+	"set":        redactSetValues,
+	"set-string": redactSetValues,
+	"set-file":   redactSetValues,
+	"set-json":   redactJSONValues,
+}
+
+var secretKey = regexp.MustCompile(`(?i)(password|passwd|secret|token|credential|apikey|api-key|api_key)`)
+
+// compLogLevelEnvVar selects how much completion logging is printed to stderr
+const compLogLevelEnvVar = "HELM_COMP_LOG_LEVEL"
+
+// compLogLevel controls which completion messages are printed to stderr.
+// Messages are always written to BASH_COMP_DEBUG_FILE when it is set.
+type compLogLevel int
+
+const (
+	compLogOff compLogLevel = iota
+	compLogError
+	compLogInfo
+	compLogDebug
+)
+
+var compLogLevels = map[string]compLogLevel{
+	"off":   compLogOff,
+	"error": compLogError,
+	"info":  compLogInfo,
+	"debug": compLogDebug,
+}
+
+// currentCompLogLevel returns the level named by $HELM_COMP_LOG_LEVEL, falling
+// back to debug output when --debug is set and no output otherwise.
+func currentCompLogLevel() compLogLevel {
+	if level, ok := compLogLevels[strings.ToLower(os.Getenv(compLogLevelEnvVar))]; ok {
+		return level
+	}
+	if settings.Debug {
+		return compLogDebug
+	}
+	return compLogOff
+}
+
+// compDebugln logs a completion trace, printing it to stderr at the debug level.
+func compDebugln(msg string) {
+	cobra.CompDebugln(msg, currentCompLogLevel() >= compLogDebug)
+}
+
+// compInfoln logs a completion message, printing it to stderr at the info level.
+func compInfoln(msg string) {
+	cobra.CompDebugln(msg, currentCompLogLevel() >= compLogInfo)
+}
+
+// compErrorln logs a completion error, printing it to stderr at the error level.
+func compErrorln(msg string) {
+	if currentCompLogLevel() >= compLogError {
+		cobra.CompErrorln(msg)
+		return
+	}
+	cobra.CompDebugln(msg, false)
+}
+
 func newCompletionCmd(out io.Writer) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "completion",
@@ -180,6 +265,76 @@ func runCompletionFish(out io.Writer, cmd *cobra.Command) error {
 	return cmd.Root().GenFishCompletion(out, !disableCompDescriptions)
 }
 
+// redactArgs returns a copy of args with the values of sensitive flags masked
+// so the command line can be safely written to the completion debug log.
+func redactArgs(args []string) []string {
+	redacted := make([]string, len(args))
+	copy(redacted, args)
+
+	for i := 0; i < len(redacted); i++ {
+		arg := redacted[i]
+		if !strings.HasPrefix(arg, "-") {
+			continue
+		}
+		name := strings.TrimLeft(arg, "-")
+		value, hasValue := "", false
+		if idx := strings.Index(name, "="); idx >= 0 {
+			name, value, hasValue = name[:idx], name[idx+1:], true
+		}
+		if !strings.HasPrefix(arg, "--") && len(arg) > 1 {
+			if long, ok := sensitiveShorthands[arg[1:2]]; ok {
+				if len(arg) > 2 && arg[2] != '=' {
+					// The value follows the shorthand directly, as in -phunter2
+					redacted[i] = arg[:2] + redactedValue
+					continue
+				}
+				name = long
+			}
+		}
+
+		switch {
+		case sensitiveFlags[name]:
+			if hasValue {
+				redacted[i] = arg[:strings.Index(arg, "=")+1] + redactedValue
+			} else if i+1 < len(redacted) {
+				i++
+				redacted[i] = redactedValue
+			}
+		case valueFlags[name] != nil:
+			redact := valueFlags[name]
+			if hasValue {
+				redacted[i] = arg[:strings.Index(arg, "=")+1] + redact(value)
+			} else if i+1 < len(redacted) {
+				i++
+				redacted[i] = redact(redacted[i])
+			}
+		}
+	}
+	return redacted
+}
+
+// redactSetValues masks the values of a --set style "key=value,key=value"
+// list whose key looks like it holds a secret.
+func redactSetValues(set string) string {
+	pairs := strings.Split(set, ",")
+	for i, pair := range pairs {
+		if idx := strings.Index(pair, "="); idx >= 0 && secretKey.MatchString(pair[:idx]) {
+			pairs[i] = pair[:idx+1] + redactedValue
+		}
+	}
+	return strings.Join(pairs, ",")
+}
+
+// redactJSONValues masks a --set-json style "key=json,key=json" list. JSON
+// values may hold commas and nested keys, so everything after the first key
+// is masked when a key anywhere in the list looks like it holds a secret.
+func redactJSONValues(set string) string {
+	if idx := strings.Index(set, "="); idx >= 0 && secretKey.MatchString(set) {
+		return set[:idx+1] + redactedValue
+	}
+	return set
+}
+
 // Function to disable file completion
 func noCompletions(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 	return nil, cobra.ShellCompDirectiveNoFileComp
@@ -18,9 +18,13 @@ package main
 
 import (
 	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
+	"helm.sh/helm/v3/internal/test/ensure"
 	"helm.sh/helm/v3/pkg/chart"
 	"helm.sh/helm/v3/pkg/release"
 )
@@ -63,6 +67,19 @@ func TestCompletionFileCompletion(t *testing.T) {
 	checkFileCompletion(t, "completion fish", false)
 }
 
+func TestCompletionShellCompletion(t *testing.T) {
+	tests := []cmdTestCase{{
+		name:   "completion for shells",
+		cmd:    "__complete completion ''",
+		golden: "output/completion-shell-comp.txt",
+	}, {
+		name:   "completion for shells with prefix",
+		cmd:    "__complete completion f",
+		golden: "output/completion-shell-filtered-comp.txt",
+	}}
+	runTestCmd(t, tests)
+}
+
 func checkReleaseCompletion(t *testing.T, cmdName string, multiReleasesAllowed bool) {
 	multiReleaseTestGolden := "output/empty_nofile_comp.txt"
 	if multiReleasesAllowed {
@@ -91,3 +108,128 @@ func checkReleaseCompletion(t *testing.T, cmdName string, multiReleasesAllowed b
 		runTestCmd(t, []cmdTestCase{test})
 	}
 }
+
+func TestRedactArgs(t *testing.T) {
+	tests := []struct {
+		args   []string
+		expect []string
+	}{
+		{
+			args:   []string{"--password", "hunter2", "--username", "me"},
+			expect: []string{"--password", "******", "--username", "me"},
+		},
+		{
+			args:   []string{"--token=abc", "-u", "keep"},
+			expect: []string{"--token=******", "-u", "keep"},
+		},
+		{
+			args:   []string{"-p", "hunter2", "-p=hunter2", "-phunter2", "-phun=ter2", "--password=hunter2"},
+			expect: []string{"-p", "******", "-p=******", "-p******", "-p******", "--password=******"},
+		},
+		{
+			args:   []string{"--set", "image.tag=1.0,db.password=s3cret", "--set-string=apiToken=xyz"},
+			expect: []string{"--set", "image.tag=1.0,db.password=******", "--set-string=apiToken=******"},
+		},
+		{
+			args:   []string{"--set-json", `db={"user":"me","password":"s3cret"}`, "--set-json=replicas=3"},
+			expect: []string{"--set-json", "db=******", "--set-json=replicas=3"},
+		},
+		{
+			args:   []string{"release", "--password"},
+			expect: []string{"release", "--password"},
+		},
+	}
+
+	for _, tt := range tests {
+		args := append([]string{}, tt.args...)
+		got := redactArgs(tt.args)
+		if strings.Join(got, " ") != strings.Join(tt.expect, " ") {
+			t.Errorf("redactArgs(%v) = %v, expected %v", tt.args, got, tt.expect)
+		}
+		if strings.Join(args, " ") != strings.Join(tt.args, " ") {
+			t.Errorf("redactArgs modified its input: %v", tt.args)
+		}
+	}
+}
+
+func TestCompletionDebugRedaction(t *testing.T) {
+	defer resetEnv()()
+
+	debugFile := filepath.Join(ensure.TempDir(t), "comp-debug.log")
+	os.Setenv("BASH_COMP_DEBUG_FILE", debugFile)
+	settings.PluginsDirectory = "testdata/helmhome/helm/plugins"
+
+	if _, _, err := executeActionCommand("__complete args --password hunter2 --set db.password=s3cret ''"); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := ioutil.ReadFile(debugFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := string(b)
+	for _, secret := range []string{"hunter2", "s3cret"} {
+		if strings.Contains(out, secret) {
+			t.Errorf("Expected %q to be redacted from the debug output:\n%s", secret, out)
+		}
+	}
+	if !strings.Contains(out, "--password ******") {
+		t.Errorf("Expected masked password in the debug output:\n%s", out)
+	}
+}
+
+func TestCompLogLevel(t *testing.T) {
+	defer resetEnv()()
+
+	captureStderr := func(fn func()) string {
+		t.Helper()
+		r, w, err := os.Pipe()
+		if err != nil {
+			t.Fatal(err)
+		}
+		stderr := os.Stderr
+		os.Stderr = w
+		fn()
+		os.Stderr = stderr
+		w.Close()
+		b, err := ioutil.ReadAll(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(b)
+	}
+
+	tests := []struct {
+		level  string
+		debug  bool
+		expect []string
+		absent []string
+	}{
+		{level: "error", expect: []string{"an error"}, absent: []string{"some info", "a trace"}},
+		{level: "info", expect: []string{"an error", "some info"}, absent: []string{"a trace"}},
+		{level: "debug", expect: []string{"an error", "some info", "a trace"}},
+		{level: "off", debug: true, absent: []string{"an error", "some info", "a trace"}},
+		{debug: true, expect: []string{"an error", "some info", "a trace"}},
+		{absent: []string{"an error", "some info", "a trace"}},
+	}
+
+	for _, tt := range tests {
+		os.Setenv(compLogLevelEnvVar, tt.level)
+		settings.Debug = tt.debug
+		out := captureStderr(func() {
+			compErrorln("an error")
+			compInfoln("some info")
+			compDebugln("a trace")
+		})
+		for _, msg := range tt.expect {
+			if !strings.Contains(out, msg) {
+				t.Errorf("level %q, debug %t: expected %q on stderr, got %q", tt.level, tt.debug, msg, out)
+			}
+		}
+		for _, msg := range tt.absent {
+			if strings.Contains(out, msg) {
+				t.Errorf("level %q, debug %t: expected no %q on stderr, got %q", tt.level, tt.debug, msg, out)
+			}
+		}
+	}
+}
@@ -20,8 +20,10 @@ import (
 	"flag"
 	"fmt"
 	"log"
+	"os"
 	"path/filepath"
 	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/spf13/cobra"
@@ -38,12 +40,113 @@ import (
 
 const outputFlag = "output"
 const postRenderFlag = "post-renderer"
+const postRenderViaFileFlag = "post-render-via-file"
 
 func addValueOptionsFlags(f *pflag.FlagSet, v *values.Options) {
 	f.StringSliceVarP(&v.ValueFiles, "values", "f", []string{}, "specify values in a YAML file or a URL (can specify multiple)")
 	f.StringArrayVar(&v.Values, "set", []string{}, "set values on the command line (can specify multiple or separate values with commas: key1=val1,key2=val2)")
 	f.StringArrayVar(&v.StringValues, "set-string", []string{}, "set STRING values on the command line (can specify multiple or separate values with commas: key1=val1,key2=val2)")
 	f.StringArrayVar(&v.FileValues, "set-file", []string{}, "set values from respective files specified via the command line (can specify multiple or separate values with commas: key1=path1,key2=path2)")
+	f.StringArrayVar(&v.JSONValues, "set-json", []string{}, "set JSON values on the command line (can specify multiple or separate values with commas: key1=jsonval1,key2=jsonval2)")
+	f.BoolVar(&v.ResolveIncludes, "resolve-includes", false, "merge the files listed under the __include__ key of local values files into them")
+}
+
+// bindSetFileCompletion completes the file path in the key=path values of the
+// --set-file flag
+func bindSetFileCompletion(cmd *cobra.Command) {
+	err := cmd.RegisterFlagCompletionFunc("set-file", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return compSetFileFlag(toComplete)
+	})
+
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+func compSetFileFlag(toComplete string) ([]string, cobra.ShellCompDirective) {
+	// Only the last of several comma separated key=path pairs is being completed
+	current := toComplete[strings.LastIndex(toComplete, ",")+1:]
+	eq := strings.Index(current, "=")
+	if eq < 0 {
+		// The key is still being typed
+		return nil, cobra.ShellCompDirectiveNoSpace | cobra.ShellCompDirectiveNoFileComp
+	}
+
+	prefix := toComplete[:len(toComplete)-len(current)+eq+1]
+	matches, _ := filepath.Glob(current[eq+1:] + "*")
+
+	var paths []string
+	for _, match := range matches {
+		if info, err := os.Stat(match); err == nil && info.IsDir() {
+			match += string(filepath.Separator)
+		}
+		paths = append(paths, prefix+match)
+	}
+	return paths, cobra.ShellCompDirectiveNoSpace | cobra.ShellCompDirectiveNoFileComp
+}
+
+// fileFlagExtensions lists the file extensions offered when completing the flags
+// that take a values, keyring or TLS file
+var fileFlagExtensions = map[string][]string{
+	"values":    {"yaml", "yml", "json"},
+	"keyring":   {"gpg", "kbx"},
+	"ca-file":   {"pem", "crt"},
+	"cert-file": {"pem", "crt", "cert"},
+	"key-file":  {"pem", "key"},
+}
+
+// bindFileFlagCompletions completes the values, keyring and TLS file flags of cmd
+// and of all its sub-commands with files that have one of the extensions in
+// fileFlagExtensions
+func bindFileFlagCompletions(cmd *cobra.Command) {
+	for name, exts := range fileFlagExtensions {
+		if cmd.LocalFlags().Lookup(name) == nil {
+			continue
+		}
+		exts := exts
+		err := cmd.RegisterFlagCompletionFunc(name, func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			return exts, cobra.ShellCompDirectiveFilterFileExt
+		})
+
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+	for _, c := range cmd.Commands() {
+		bindFileFlagCompletions(c)
+	}
+}
+
+// enumFlagValue is implemented by flag values that accept one of a fixed set of
+// values
+type enumFlagValue interface {
+	pflag.Value
+	AllowedValues() []string
+}
+
+// bindEnumFlagCompletions completes the flags of cmd and of all its sub-commands
+// whose value implements enumFlagValue with their allowed values, unless a
+// completion function is already registered for the flag
+func bindEnumFlagCompletions(cmd *cobra.Command) {
+	cmd.LocalFlags().VisitAll(func(flag *pflag.Flag) {
+		enum, ok := flag.Value.(enumFlagValue)
+		if !ok {
+			return
+		}
+		// An error means the command registered its own completion function
+		cmd.RegisterFlagCompletionFunc(flag.Name, func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			var values []string
+			for _, v := range enum.AllowedValues() {
+				if strings.HasPrefix(v, toComplete) {
+					values = append(values, v)
+				}
+			}
+			return values, cobra.ShellCompDirectiveNoFileComp
+		})
+	})
+	for _, c := range cmd.Commands() {
+		bindEnumFlagCompletions(c)
+	}
 }
 
 func addChartPathOptionsFlags(f *pflag.FlagSet, c *action.ChartPathOptions) {
@@ -112,26 +215,42 @@ func (o *outputValue) Set(s string) error {
 }
 
 func bindPostRenderFlag(cmd *cobra.Command, varRef *postrender.PostRenderer) {
-	cmd.Flags().Var(&postRenderer{varRef}, postRenderFlag, "the path to an executable to be used for post rendering. If it exists in $PATH, the binary will be used, otherwise it will try to look for the executable at the given path")
+	p := &postRenderer{renderer: varRef}
+	cmd.Flags().Var(p, postRenderFlag, "the path to an executable to be used for post rendering. If it exists in $PATH, the binary will be used, otherwise it will try to look for the executable at the given path")
+	f := cmd.Flags().VarPF(postRenderViaFile{p}, postRenderViaFileFlag, "", "pass the rendered manifests to the post-renderer as the path of a temporary file, given as its last argument, instead of on stdin")
+	f.NoOptDefVal = "true"
 }
 
 type postRenderer struct {
-	renderer *postrender.PostRenderer
+	renderer   *postrender.PostRenderer
+	binaryPath string
+	viaFile    bool
 }
 
-func (p postRenderer) String() string {
+func (p *postRenderer) String() string {
 	return "exec"
 }
 
-func (p postRenderer) Type() string {
+func (p *postRenderer) Type() string {
 	return "postrenderer"
 }
 
-func (p postRenderer) Set(s string) error {
-	if s == "" {
+func (p *postRenderer) Set(s string) error {
+	p.binaryPath = s
+	return p.update()
+}
+
+// update creates the post-renderer from the flags set so far, so that they
+// can be given in any order.
+func (p *postRenderer) update() error {
+	if p.binaryPath == "" {
 		return nil
 	}
-	pr, err := postrender.NewExec(s)
+	newExec := postrender.NewExec
+	if p.viaFile {
+		newExec = postrender.NewExecViaFile
+	}
+	pr, err := newExec(p.binaryPath)
 	if err != nil {
 		return err
 	}
@@ -139,6 +258,28 @@ func (p postRenderer) Set(s string) error {
 	return nil
 }
 
+// postRenderViaFile is the value of the --post-render-via-file flag.
+type postRenderViaFile struct {
+	p *postRenderer
+}
+
+func (v postRenderViaFile) String() string {
+	return strconv.FormatBool(v.p.viaFile)
+}
+
+func (v postRenderViaFile) Type() string {
+	return "bool"
+}
+
+func (v postRenderViaFile) Set(s string) error {
+	viaFile, err := strconv.ParseBool(s)
+	if err != nil {
+		return err
+	}
+	v.p.viaFile = viaFile
+	return v.p.update()
+}
+
 func compVersionFlag(chartRef string, toComplete string) ([]string, cobra.ShellCompDirective) {
 	chartInfo := strings.Split(chartRef, "/")
 	if len(chartInfo) != 2 {
@@ -17,10 +17,19 @@ limitations under the License.
 package main
 
 import (
+	"bytes"
 	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
 	"testing"
 
+	"github.com/spf13/cobra"
+
+	"helm.sh/helm/v3/internal/test/ensure"
 	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/postrender"
 	"helm.sh/helm/v3/pkg/release"
 	helmtime "helm.sh/helm/v3/pkg/time"
 )
@@ -86,3 +95,132 @@ func outputFlagCompletionTest(t *testing.T, cmdName string) {
 	}}
 	runTestCmd(t, tests)
 }
+
+func TestSetFileFlagCompletion(t *testing.T) {
+	tests := []cmdTestCase{{
+		name:   "completion for set-file flag after equal sign",
+		cmd:    "__complete install --set-file config=testdata/testcharts/alpine/",
+		golden: "output/set-file-comp.txt",
+	}, {
+		name:   "completion for set-file flag after several pairs",
+		cmd:    "__complete upgrade --set-file a=b,config=testdata/testcharts/alpine/e",
+		golden: "output/set-file-multiple-comp.txt",
+	}, {
+		name:   "completion for set-file flag before equal sign",
+		cmd:    "__complete lint --set-file conf",
+		golden: "output/set-file-key-comp.txt",
+	}}
+	runTestCmd(t, tests)
+}
+
+func TestFileFlagCompletion(t *testing.T) {
+	tests := []cmdTestCase{{
+		name:   "completion for values flag",
+		cmd:    "__complete install --values ''",
+		golden: "output/values-flag-comp.txt",
+	}, {
+		name:   "completion for values shorthand flag",
+		cmd:    "__complete upgrade -f ''",
+		golden: "output/values-flag-comp.txt",
+	}, {
+		name:   "completion for keyring flag",
+		cmd:    "__complete verify --keyring ''",
+		golden: "output/keyring-flag-comp.txt",
+	}, {
+		name:   "completion for ca-file flag",
+		cmd:    "__complete repo add --ca-file ''",
+		golden: "output/ca-file-flag-comp.txt",
+	}, {
+		name:   "completion for cert-file flag",
+		cmd:    "__complete pull --cert-file ''",
+		golden: "output/cert-file-flag-comp.txt",
+	}, {
+		name:   "completion for key-file flag",
+		cmd:    "__complete install --key-file ''",
+		golden: "output/key-file-flag-comp.txt",
+	}}
+	runTestCmd(t, tests)
+}
+
+type testEnumValue string
+
+func (v *testEnumValue) String() string { return string(*v) }
+func (v *testEnumValue) Type() string   { return "level" }
+func (v *testEnumValue) Set(s string) error {
+	*v = testEnumValue(s)
+	return nil
+}
+func (v *testEnumValue) AllowedValues() []string {
+	return []string{"debug", "info", "warn", "error"}
+}
+
+func TestEnumFlagCompletion(t *testing.T) {
+	var level, custom testEnumValue
+	root := &cobra.Command{Use: "root"}
+	sub := &cobra.Command{Use: "sub", Run: func(*cobra.Command, []string) {}}
+	sub.Flags().Var(&level, "level", "log level")
+	sub.Flags().Var(&custom, "custom", "log level with its own completion")
+	root.AddCommand(sub)
+
+	err := sub.RegisterFlagCompletionFunc("custom", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{"mine"}, cobra.ShellCompDirectiveNoFileComp
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	bindEnumFlagCompletions(root)
+
+	for _, tt := range []struct {
+		args   []string
+		expect string
+	}{
+		{[]string{"sub", "--level", ""}, "debug\ninfo\nwarn\nerror\n:4\n"},
+		{[]string{"sub", "--level", "d"}, "debug\n:4\n"},
+		{[]string{"sub", "--custom", ""}, "mine\n:4\n"},
+	} {
+		buf := new(bytes.Buffer)
+		root.SetOut(buf)
+		root.SetErr(ioutil.Discard)
+		root.SetArgs(append([]string{cobra.ShellCompRequestCmd}, tt.args...))
+		if err := root.Execute(); err != nil {
+			t.Fatal(err)
+		}
+		if buf.String() != tt.expect {
+			t.Errorf("completing %v: expected %q, got %q", tt.args, tt.expect, buf.String())
+		}
+	}
+}
+
+func TestPostRenderViaFileFlag(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the post-renderer is a shell script, skipping on windows")
+	}
+	tmpdir := ensure.TempDir(t)
+	defer os.RemoveAll(tmpdir)
+	script := filepath.Join(tmpdir, "post-render.sh")
+	if err := ioutil.WriteFile(script, []byte("#!/bin/sh\ncat \"$1\"\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, args := range [][]string{
+		{"--post-renderer", script, "--post-render-via-file"},
+		{"--post-render-via-file", "--post-renderer", script},
+	} {
+		var pr postrender.PostRenderer
+		cmd := &cobra.Command{}
+		bindPostRenderFlag(cmd, &pr)
+		if err := cmd.ParseFlags(args); err != nil {
+			t.Fatal(err)
+		}
+		if pr == nil {
+			t.Fatalf("%v: expected a post-renderer", args)
+		}
+		out, err := pr.Run(bytes.NewBufferString("kind: ConfigMap\n"))
+		if err != nil {
+			t.Fatalf("%v: %s", args, err)
+		}
+		if out.String() != "kind: ConfigMap\n" {
+			t.Errorf("%v: expected the manifest to be read from the file, got %q", args, out)
+		}
+	}
+}
@@ -65,6 +65,12 @@ func TestInstall(t *testing.T) {
 			cmd:    "install virgil testdata/testcharts/alpine --set test.Name=bar",
 			golden: "output/install-with-values.txt",
 		},
+		// Install, listing the resources of the release
+		{
+			name:   "install with resources",
+			cmd:    "install virgil testdata/testcharts/alpine --set test.Name=bar --namespace default --show-resources",
+			golden: "output/install-with-resources.txt",
+		},
 		// Install, values from cli via multiple --set
 		{
 			name:   "install with multiple values",
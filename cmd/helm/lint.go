@@ -21,12 +21,14 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 
 	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chartutil"
 	"helm.sh/helm/v3/pkg/cli/values"
 	"helm.sh/helm/v3/pkg/getter"
 )
@@ -43,12 +45,23 @@ or recommendation, it will emit [WARNING] messages.
 func newLintCmd(out io.Writer) *cobra.Command {
 	client := action.NewLint()
 	valueOpts := &values.Options{}
+	var showCapabilities bool
+	var kubeVersion string
+	var parallel int
 
 	cmd := &cobra.Command{
 		Use:   "lint PATH",
 		Short: "examine a chart for possible issues",
 		Long:  longLintHelp,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if kubeVersion != "" {
+				parsedKubeVersion, err := chartutil.ParseKubeVersion(kubeVersion)
+				if err != nil {
+					return fmt.Errorf("invalid kube version '%s': %s", kubeVersion, err)
+				}
+				client.KubeVersion = parsedKubeVersion
+			}
+
 			paths := []string{"."}
 			if len(args) > 0 {
 				paths = args
@@ -77,10 +90,11 @@ func newLintCmd(out io.Writer) *cobra.Command {
 			var message strings.Builder
 			failed := 0
 
-			for _, path := range paths {
+			results := client.RunParallel(paths, vals, parallel)
+			for i, path := range paths {
 				fmt.Fprintf(&message, "==> Linting %s\n", path)
 
-				result := client.Run([]string{path}, vals)
+				result := results[i]
 
 				// All the Errors that are generated by a chart
 				// that failed a lint will be included in the
@@ -96,6 +110,10 @@ func newLintCmd(out io.Writer) *cobra.Command {
 					fmt.Fprintf(&message, "%s\n", msg)
 				}
 
+				if showCapabilities && result.Capabilities != nil {
+					printCapabilities(&message, result.Capabilities)
+				}
+
 				if len(result.Errors) != 0 {
 					failed++
 				}
@@ -120,7 +138,24 @@ func newLintCmd(out io.Writer) *cobra.Command {
 	f := cmd.Flags()
 	f.BoolVar(&client.Strict, "strict", false, "fail on lint warnings")
 	f.BoolVar(&client.WithSubcharts, "with-subcharts", false, "lint dependent charts")
+	f.StringVar(&client.ReleaseName, "release-name", "", "release name used to render the templates")
+	f.BoolVar(&showCapabilities, "show-capabilities", false, "print the capabilities the templates were rendered with")
+	f.BoolVar(&client.CheckOverrides, "check-overrides", false, "warn about values set with --set or -f that the chart does not use")
+	f.IntVar(&parallel, "parallel", 1, "number of charts to lint at the same time")
+	f.StringVar(&kubeVersion, "kube-version", "", "Kubernetes version used for Capabilities.KubeVersion")
 	addValueOptionsFlags(f, valueOpts)
+	bindSetFileCompletion(cmd)
 
 	return cmd
 }
+
+// printCapabilities writes the Kubernetes version and API versions that were
+// available to the templates during lint.
+func printCapabilities(out io.Writer, caps *chartutil.Capabilities) {
+	fmt.Fprintf(out, "Capabilities:\n  KubeVersion: %s\n  APIVersions:\n", caps.KubeVersion.Version)
+	apiVersions := append([]string(nil), caps.APIVersions...)
+	sort.Strings(apiVersions)
+	for _, v := range apiVersions {
+		fmt.Fprintf(out, "    %s\n", v)
+	}
+}
@@ -18,7 +18,10 @@ package main
 
 import (
 	"fmt"
+	"strings"
 	"testing"
+
+	"helm.sh/helm/v3/pkg/chartutil"
 )
 
 func TestLintCmdWithSubchartsFlag(t *testing.T) {
@@ -41,3 +44,43 @@ func TestLintFileCompletion(t *testing.T) {
 	checkFileCompletion(t, "lint", true)
 	checkFileCompletion(t, "lint mypath", true) // Multiple paths can be given
 }
+
+func TestLintCmdShowCapabilities(t *testing.T) {
+	_, out, err := executeActionCommand("lint --show-capabilities testdata/testcharts/alpine")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, expect := range []string{
+		"Capabilities:\n",
+		fmt.Sprintf("  KubeVersion: %s\n", chartutil.DefaultCapabilities.KubeVersion.Version),
+		"  APIVersions:\n",
+		"    apps/v1\n",
+		"    v1\n",
+	} {
+		if !strings.Contains(out, expect) {
+			t.Errorf("expected output to contain %q, got:\n%s", expect, out)
+		}
+	}
+
+	_, out, err = executeActionCommand("lint testdata/testcharts/alpine")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(out, "Capabilities:") {
+		t.Errorf("expected capabilities to be printed only on request, got:\n%s", out)
+	}
+}
+
+func TestLintCmdKubeVersion(t *testing.T) {
+	_, out, err := executeActionCommand("lint --kube-version 1.21 --show-capabilities testdata/testcharts/alpine")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, "  KubeVersion: v1.21.0\n") {
+		t.Errorf("Expected the lint to use Kubernetes v1.21.0, got:\n%s", out)
+	}
+
+	if _, _, err := executeActionCommand("lint --kube-version invalid testdata/testcharts/alpine"); err == nil {
+		t.Error("Expected an error for an invalid kube version")
+	}
+}
@@ -344,11 +344,11 @@ func pluginDynamicComp(plug *plugin.Plugin, cmd *cobra.Command, args []string, t
 	}
 	plugin.SetupPluginEnv(settings, md.Name, plug.Dir)
 
-	cobra.CompDebugln(fmt.Sprintf("calling %s with args %v", main, argv), settings.Debug)
+	compInfoln(fmt.Sprintf("calling %s with args %v", main, redactArgs(argv)))
 	buf := new(bytes.Buffer)
 	if err := callPluginExecutable(md.Name, main, argv, buf); err != nil {
 		// The dynamic completion file is optional for a plugin, so this error is ok.
-		cobra.CompDebugln(fmt.Sprintf("Unable to call %s: %v", main, err.Error()), settings.Debug)
+		compDebugln(fmt.Sprintf("Unable to call %s: %v", main, err.Error()))
 		return nil, cobra.ShellCompDirectiveDefault
 	}
 
@@ -277,6 +277,16 @@ func TestPluginDynamicCompletion(t *testing.T) {
 		cmd:    "__complete echo -n mynamespace ''",
 		golden: "output/plugin_echo_no_directive.txt",
 		rels:   []*release.Release{},
+	}, {
+		name:   "completion for plugin sub-commands",
+		cmd:    "__complete fullenv ''",
+		golden: "output/plugin_fullenv_subcmd_comp.txt",
+		rels:   []*release.Release{},
+	}, {
+		name:   "completion for plugin sub-command valid args",
+		cmd:    "__complete fullenv full more ''",
+		golden: "output/plugin_fullenv_validargs_comp.txt",
+		rels:   []*release.Release{},
 	}}
 	for _, test := range tests {
 		settings.PluginsDirectory = "testdata/helmhome/helm/plugins"
@@ -34,11 +34,20 @@ import (
 
 const registryLoginDesc = `
 Authenticate to a remote registry.
+
+When no password is given and stdin is not a terminal, the username and
+password are read from the HELM_REGISTRY_USERNAME and HELM_REGISTRY_PASSWORD
+environment variables before prompting for them.
 `
 
+// stdinIsTerminal reports whether stdin is an interactive terminal.
+var stdinIsTerminal = func() bool {
+	return term.IsTerminal(os.Stdin.Fd())
+}
+
 func newRegistryLoginCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
-	var usernameOpt, passwordOpt string
-	var passwordFromStdinOpt, insecureOpt bool
+	var usernameOpt, passwordOpt, tokenFileOpt string
+	var passwordFromStdinOpt, insecureOpt, verifyOpt bool
 
 	cmd := &cobra.Command{
 		Use:    "login [host]",
@@ -48,13 +57,17 @@ func newRegistryLoginCmd(cfg *action.Configuration, out io.Writer) *cobra.Comman
 		Hidden: !FeatureGateOCI.IsEnabled(),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			hostname := args[0]
-
-			username, password, err := getUsernamePassword(usernameOpt, passwordOpt, passwordFromStdinOpt)
-			if err != nil {
-				return err
+			opts := action.LoginOptions{TokenFile: tokenFileOpt, Insecure: insecureOpt, Verify: verifyOpt}
+
+			if tokenFileOpt == "" {
+				username, password, err := getUsernamePassword(usernameOpt, passwordOpt, passwordFromStdinOpt)
+				if err != nil {
+					return err
+				}
+				opts.Username, opts.Password = username, password
 			}
 
-			return action.NewRegistryLogin(cfg).Run(out, hostname, username, password, insecureOpt)
+			return action.NewRegistryLogin(cfg).RunWithOptions(out, hostname, opts)
 		},
 	}
 
@@ -62,7 +75,9 @@ func newRegistryLoginCmd(cfg *action.Configuration, out io.Writer) *cobra.Comman
 	f.StringVarP(&usernameOpt, "username", "u", "", "registry username")
 	f.StringVarP(&passwordOpt, "password", "p", "", "registry password or identity token")
 	f.BoolVarP(&passwordFromStdinOpt, "password-stdin", "", false, "read password or identity token from stdin")
+	f.StringVar(&tokenFileOpt, "token-file", "", "read an identity token from this file instead of a username and password")
 	f.BoolVarP(&insecureOpt, "insecure", "", false, "allow connections to TLS registry without certs")
+	f.BoolVar(&verifyOpt, "verify", false, "check the credentials with the registry without storing them")
 
 	return cmd
 }
@@ -80,7 +95,18 @@ func getUsernamePassword(usernameOpt string, passwordOpt string, passwordFromStd
 		}
 		password = strings.TrimSuffix(string(passwordFromStdin), "\n")
 		password = strings.TrimSuffix(password, "\r")
-	} else if password == "" {
+	} else if password != "" {
+		warning("Using --password via the CLI is insecure. Use --password-stdin.")
+	} else {
+		if !stdinIsTerminal() {
+			if username == "" {
+				username = os.Getenv("HELM_REGISTRY_USERNAME")
+			}
+			password = os.Getenv("HELM_REGISTRY_PASSWORD")
+		}
+		if password != "" {
+			return username, password, nil
+		}
 		if username == "" {
 			username, err = readLine("Username: ", false)
 			if err != nil {
@@ -103,8 +129,6 @@ func getUsernamePassword(usernameOpt string, passwordOpt string, passwordFromStd
 				return "", "", errors.New("password required")
 			}
 		}
-	} else {
-		warning("Using --password via the CLI is insecure. Use --password-stdin.")
 	}
 
 	return username, password, nil
@@ -0,0 +1,55 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"os"
+	"testing"
+)
+
+func TestGetUsernamePasswordFromEnv(t *testing.T) {
+	defer resetEnv()()
+	oldIsTerminal := stdinIsTerminal
+	defer func() { stdinIsTerminal = oldIsTerminal }()
+	stdinIsTerminal = func() bool { return false }
+
+	os.Setenv("HELM_REGISTRY_USERNAME", "env-user")
+	os.Setenv("HELM_REGISTRY_PASSWORD", "env-pass")
+
+	tests := []struct {
+		name         string
+		usernameOpt  string
+		passwordOpt  string
+		wantUsername string
+		wantPassword string
+	}{
+		{name: "env", wantUsername: "env-user", wantPassword: "env-pass"},
+		{name: "username flag", usernameOpt: "flag-user", wantUsername: "flag-user", wantPassword: "env-pass"},
+		{name: "password flag", usernameOpt: "flag-user", passwordOpt: "flag-pass", wantUsername: "flag-user", wantPassword: "flag-pass"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			username, password, err := getUsernamePassword(tt.usernameOpt, tt.passwordOpt, false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if username != tt.wantUsername || password != tt.wantPassword {
+				t.Errorf("Expected %q/%q, got %q/%q", tt.wantUsername, tt.wantPassword, username, password)
+			}
+		})
+	}
+}
@@ -29,13 +29,13 @@ import (
 var repoHelm = `
 This command consists of multiple subcommands to interact with chart repositories.
 
-It can be used to add, remove, list, and index chart repositories.
+It can be used to add, remove, rename, list, test, and index chart repositories.
 `
 
 func newRepoCmd(out io.Writer) *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "repo add|remove|list|index|update [ARGS]",
-		Short: "add, list, remove, update, and index chart repositories",
+		Use:   "repo add|remove|rename|list|test|index|update [ARGS]",
+		Short: "add, list, remove, rename, test, update, and index chart repositories",
 		Long:  repoHelm,
 		Args:  require.NoArgs,
 	}
@@ -43,6 +43,8 @@ func newRepoCmd(out io.Writer) *cobra.Command {
 	cmd.AddCommand(newRepoAddCmd(out))
 	cmd.AddCommand(newRepoListCmd(out))
 	cmd.AddCommand(newRepoRemoveCmd(out))
+	cmd.AddCommand(newRepoRenameCmd(out))
+	cmd.AddCommand(newRepoTestCmd(out))
 	cmd.AddCommand(newRepoIndexCmd(out))
 	cmd.AddCommand(newRepoUpdateCmd(out))
 
@@ -21,8 +21,14 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
 	"os"
+	"path"
 	"path/filepath"
+	"reflect"
+	"regexp"
 	"strings"
 	"time"
 
@@ -43,6 +49,48 @@ var deprecatedRepos = map[string]string{
 	"//kubernetes-charts-incubator.storage.googleapis.com": "https://charts.helm.sh/incubator",
 }
 
+var (
+	// gcsBucketLabel matches a single dot-separated component of a Google Cloud
+	// Storage bucket name. See https://cloud.google.com/storage/docs/naming-buckets
+	gcsBucketLabel = regexp.MustCompile(`^[a-z0-9_-]{1,63}$`)
+	// ociRepositoryPath matches the path component of an OCI repository reference.
+	// See https://github.com/opencontainers/distribution-spec/blob/main/spec.md#pulling-manifests
+	ociRepositoryPath = regexp.MustCompile(`^[a-z0-9]+((\.|_|__|-+)[a-z0-9]+)*(/[a-z0-9]+((\.|_|__|-+)[a-z0-9]+)*)*$`)
+)
+
+var (
+	// errInvalidBucketName indicates that a gs:// URL does not name a valid bucket.
+	errInvalidBucketName = errors.New("not a valid GCS bucket name")
+	// errMissingRegistryHost indicates that an oci:// URL has no registry host.
+	errMissingRegistryHost = errors.New("missing registry host")
+	// errInvalidRepositoryPath indicates that an oci:// URL has a malformed repository path.
+	errInvalidRepositoryPath = errors.New("not a valid OCI repository path")
+	// errRepositoryReference indicates that an oci:// URL points at a tag or digest instead of a repository.
+	errRepositoryReference = errors.New("repository URLs must not include a tag or digest")
+	// errUnsupportedScheme indicates that a URL scheme is neither built in nor provided by a getter plugin.
+	errUnsupportedScheme = errors.New("not supported, use http, https, gs, oci or a scheme provided by a getter plugin")
+)
+
+// repoSchemes are the URL schemes accepted for a repository without a getter
+// plugin providing them.
+var repoSchemes = []string{"http", "https", "gs", "oci"}
+
+// repoURLError records why a repository URL was rejected.
+type repoURLError struct {
+	URL    string
+	Scheme string
+	// Part is the portion of the URL that failed validation: "scheme", "bucket", "host" or "path".
+	Part  string
+	Value string
+	Err   error
+}
+
+func (e *repoURLError) Error() string {
+	return fmt.Sprintf("invalid repository URL %q: %s %q is %s", e.URL, e.Part, e.Value, e.Err)
+}
+
+func (e *repoURLError) Unwrap() error { return e.Err }
+
 type repoAddOptions struct {
 	name                 string
 	url                  string
@@ -51,6 +99,7 @@ type repoAddOptions struct {
 	passCredentialsAll   bool
 	forceUpdate          bool
 	allowDeprecatedRepos bool
+	headers              []string
 
 	certFile              string
 	keyFile               string
@@ -93,6 +142,7 @@ func newRepoAddCmd(out io.Writer) *cobra.Command {
 	f.BoolVar(&o.insecureSkipTLSverify, "insecure-skip-tls-verify", false, "skip tls certificate checks for the repository")
 	f.BoolVar(&o.allowDeprecatedRepos, "allow-deprecated-repos", false, "by default, this command will not allow adding official repos that have been permanently deleted. This disables that behavior")
 	f.BoolVar(&o.passCredentialsAll, "pass-credentials", false, "pass credentials to all domains")
+	f.StringArrayVar(&o.headers, "header", []string{}, `custom HTTP header sent when fetching from the repository, in the form "Name: value" (can specify multiple)`)
 
 	return cmd
 }
@@ -107,8 +157,17 @@ func (o *repoAddOptions) run(out io.Writer) error {
 		}
 	}
 
+	if err := validateRepoURL(o.url, getter.All(settings)); err != nil {
+		return err
+	}
+
+	headers, err := parseRepoHeaders(o.headers)
+	if err != nil {
+		return err
+	}
+
 	// Ensure the file directory exists as it is required for file locking
-	err := os.MkdirAll(filepath.Dir(o.repoFile), os.ModePerm)
+	err = os.MkdirAll(filepath.Dir(o.repoFile), os.ModePerm)
 	if err != nil && !os.IsExist(err) {
 		return err
 	}
@@ -163,6 +222,7 @@ func (o *repoAddOptions) run(out io.Writer) error {
 		KeyFile:               o.keyFile,
 		CAFile:                o.caFile,
 		InsecureSkipTLSverify: o.insecureSkipTLSverify,
+		Headers:               headers,
 	}
 
 	// If the repo exists do one of two things:
@@ -170,7 +230,7 @@ func (o *repoAddOptions) run(out io.Writer) error {
 	// 2. When the config is different require --force-update
 	if !o.forceUpdate && f.Has(o.name) {
 		existing := f.Get(o.name)
-		if c != *existing {
+		if !reflect.DeepEqual(c, *existing) {
 
 			// The input coming in for the name is different from what is already
 			// configured. Return an error.
@@ -199,6 +259,112 @@ func (o *repoAddOptions) run(out io.Writer) error {
 	if err := f.WriteFile(o.repoFile, 0644); err != nil {
 		return err
 	}
+	if len(headers) > 0 {
+		// Custom headers usually carry credentials, so the file is only
+		// readable by its owner once it holds them.
+		if err := os.Chmod(o.repoFile, 0600); err != nil {
+			return err
+		}
+	}
 	fmt.Fprintf(out, "%q has been added to your repositories\n", o.name)
 	return nil
 }
+
+// parseRepoHeaders parses headers given as "Name: value". The values are never
+// included in errors, as they often hold credentials.
+func parseRepoHeaders(headers []string) (map[string]string, error) {
+	if len(headers) == 0 {
+		return nil, nil
+	}
+	parsed := make(map[string]string, len(headers))
+	for i, header := range headers {
+		parts := strings.SplitN(header, ":", 2)
+		name := strings.TrimSpace(parts[0])
+		if len(parts) != 2 || name == "" || strings.ContainsAny(name, " \t") {
+			return nil, errors.Errorf("invalid header #%d, expected \"Name: value\"", i+1)
+		}
+		parsed[http.CanonicalHeaderKey(name)] = strings.TrimSpace(parts[1])
+	}
+	return parsed, nil
+}
+
+// validateRepoURL checks the scheme of a repository URL against repoSchemes
+// and the schemes of the providers, then applies scheme-specific checks.
+//
+// Schemes without dedicated rules (http, https, and those provided by getter
+// plugins) are accepted as-is so that the getter reports any problem.
+func validateRepoURL(repoURL string, providers getter.Providers) error {
+	u, err := url.Parse(repoURL)
+	if err != nil {
+		return errors.Wrapf(err, "invalid repository URL %q", repoURL)
+	}
+	if !supportedRepoScheme(u.Scheme, providers) {
+		return &repoURLError{URL: repoURL, Scheme: u.Scheme, Part: "scheme", Value: u.Scheme, Err: errUnsupportedScheme}
+	}
+
+	switch u.Scheme {
+	case "gs":
+		if err := validateGCSBucketName(u.Host); err != nil {
+			return &repoURLError{URL: repoURL, Scheme: u.Scheme, Part: "bucket", Value: u.Host, Err: err}
+		}
+	case "oci":
+		if u.Host == "" {
+			return &repoURLError{URL: repoURL, Scheme: u.Scheme, Part: "host", Value: u.Host, Err: errMissingRegistryHost}
+		}
+		p := strings.Trim(u.Path, "/")
+		if strings.Contains(p, "@") || strings.Contains(path.Base(p), ":") {
+			return &repoURLError{URL: repoURL, Scheme: u.Scheme, Part: "path", Value: p, Err: errRepositoryReference}
+		}
+		if !ociRepositoryPath.MatchString(p) {
+			return &repoURLError{URL: repoURL, Scheme: u.Scheme, Part: "path", Value: p, Err: errInvalidRepositoryPath}
+		}
+	}
+	return nil
+}
+
+// supportedRepoScheme reports whether scheme is one of repoSchemes or is
+// provided by one of the providers.
+func supportedRepoScheme(scheme string, providers getter.Providers) bool {
+	for _, s := range repoSchemes {
+		if s == scheme {
+			return true
+		}
+	}
+	for _, p := range providers {
+		if p.Provides(scheme) {
+			return true
+		}
+	}
+	return false
+}
+
+// validateGCSBucketName checks a bucket name against the GCS naming rules:
+// names without dots are 3-63 characters, dotted names are at most 222
+// characters with each component at most 63, names start and end with a
+// letter or digit, and they may not look like an IP address or start with
+// "goog".
+func validateGCSBucketName(name string) error {
+	maxLen := 63
+	if strings.Contains(name, ".") {
+		maxLen = 222
+	}
+	if len(name) < 3 || len(name) > maxLen {
+		return errInvalidBucketName
+	}
+	if !isAlphanumeric(name[0]) || !isAlphanumeric(name[len(name)-1]) {
+		return errInvalidBucketName
+	}
+	for _, label := range strings.Split(name, ".") {
+		if !gcsBucketLabel.MatchString(label) {
+			return errInvalidBucketName
+		}
+	}
+	if net.ParseIP(name) != nil || strings.HasPrefix(name, "goog") {
+		return errInvalidBucketName
+	}
+	return nil
+}
+
+func isAlphanumeric(c byte) bool {
+	return ('a' <= c && c <= 'z') || ('0' <= c && c <= '9')
+}
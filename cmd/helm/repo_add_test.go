@@ -17,16 +17,23 @@ limitations under the License.
 package main
 
 import (
+	"errors"
 	"fmt"
 	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
 	"sync"
 	"testing"
 
 	"sigs.k8s.io/yaml"
 
 	"helm.sh/helm/v3/internal/test/ensure"
+	"helm.sh/helm/v3/pkg/getter"
 	"helm.sh/helm/v3/pkg/helmpath"
 	"helm.sh/helm/v3/pkg/helmpath/xdg"
 	"helm.sh/helm/v3/pkg/repo"
@@ -130,6 +137,81 @@ func TestRepoAdd(t *testing.T) {
 	}
 }
 
+func TestRepoAddHeaders(t *testing.T) {
+	index, err := ioutil.ReadFile("testdata/testserver/index.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Jfrog-Art-Api") != "s3cr3t" || r.Header.Get("X-Team") != "platform" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write(index)
+	}))
+	defer srv.Close()
+
+	rootDir := ensure.TempDir(t)
+	repoFile := filepath.Join(rootDir, "repositories.yaml")
+	os.Setenv(xdg.CacheHomeEnvVar, rootDir)
+
+	o := &repoAddOptions{
+		name:     "headers",
+		url:      srv.URL,
+		repoFile: repoFile,
+		headers:  []string{"x-jfrog-art-api: s3cr3t", "X-Team:platform"},
+	}
+	var out strings.Builder
+	if err := o.run(&out); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(out.String(), "s3cr3t") {
+		t.Errorf("Expected the header value to not be printed, got %q", out.String())
+	}
+
+	f, err := repo.LoadFile(repoFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]string{"X-Jfrog-Art-Api": "s3cr3t", "X-Team": "platform"}
+	if got := f.Get("headers").Headers; !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected stored headers %v, got %v", expected, got)
+	}
+	if fi, err := os.Stat(repoFile); err != nil {
+		t.Fatal(err)
+	} else if runtime.GOOS != "windows" && fi.Mode().Perm() != 0600 {
+		t.Errorf("Expected the repositories file to only be readable by its owner, got %v", fi.Mode().Perm())
+	}
+
+	// Adding the same repository again is a no-op, while different headers
+	// require --force-update.
+	if err := o.run(ioutil.Discard); err != nil {
+		t.Errorf("Expected re-adding the same repository to succeed, got %s", err)
+	}
+	o.headers = []string{"X-Jfrog-Art-Api: other", "X-Team: platform"}
+	if err := o.run(ioutil.Discard); err == nil {
+		t.Error("Expected an error when re-adding the repository with different headers")
+	}
+
+	// Without the headers the server refuses the request
+	o = &repoAddOptions{name: "no-headers", url: srv.URL, repoFile: repoFile}
+	if err := o.run(ioutil.Discard); err == nil {
+		t.Error("Expected an error when adding the repository without headers")
+	}
+}
+
+func TestParseRepoHeaders(t *testing.T) {
+	if _, err := parseRepoHeaders([]string{"X-Ok: fine", "s3cr3t-without-name"}); err == nil || strings.Contains(err.Error(), "s3cr3t") {
+		t.Errorf("Expected an error that does not include the header, got %v", err)
+	}
+	if _, err := parseRepoHeaders([]string{": value"}); err == nil {
+		t.Error("Expected an error for a header without a name")
+	}
+	if headers, err := parseRepoHeaders(nil); err != nil || headers != nil {
+		t.Errorf("Expected no headers, got %v, %v", headers, err)
+	}
+}
+
 func TestRepoAddConcurrentGoRoutines(t *testing.T) {
 	const testName = "test-name"
 	repoFile := filepath.Join(ensure.TempDir(t), "repositories.yaml")
@@ -204,3 +286,85 @@ func TestRepoAddFileCompletion(t *testing.T) {
 	checkFileCompletion(t, "repo add reponame", false)
 	checkFileCompletion(t, "repo add reponame https://example.com", false)
 }
+
+func TestRepoAddInvalidURL(t *testing.T) {
+	tmpdir := ensure.TempDir(t)
+	repoFile := filepath.Join(tmpdir, "repositories.yaml")
+
+	tests := []cmdTestCase{
+		{
+			name:      "add a repository with an invalid bucket name",
+			cmd:       fmt.Sprintf("repo add foo gs://Bad! --repository-config %s --repository-cache %s", repoFile, tmpdir),
+			wantError: true,
+		},
+		{
+			name:      "add a repository with a tagged OCI reference",
+			cmd:       fmt.Sprintf("repo add foo oci://registry.example.com/charts/app:1.0.0 --repository-config %s --repository-cache %s", repoFile, tmpdir),
+			wantError: true,
+		},
+	}
+
+	runTestCmd(t, tests)
+
+	if _, err := os.Stat(repoFile); !os.IsNotExist(err) {
+		t.Errorf("expected %s to be left untouched, got %v", repoFile, err)
+	}
+}
+
+func TestValidateRepoURL(t *testing.T) {
+	longDottedBucket := strings.Repeat("a", 63) + "." + strings.Repeat("b", 63) + ".example.com"
+
+	tests := []struct {
+		url     string
+		part    string
+		wantErr error
+	}{
+		{url: "https://charts.example.com"},
+		{url: "http://localhost:8879/charts"},
+		{url: "gs://my-charts.example_bucket/stable"},
+		{url: "gs://" + longDottedBucket},
+		{url: "oci://registry.example.com/team/charts"},
+		{url: "oci://localhost:5000/charts"},
+		{url: "gs://My_Bucket", part: "bucket", wantErr: errInvalidBucketName},
+		{url: "gs://ab", part: "bucket", wantErr: errInvalidBucketName},
+		{url: "gs://a..b", part: "bucket", wantErr: errInvalidBucketName},
+		{url: "gs://192.168.5.4", part: "bucket", wantErr: errInvalidBucketName},
+		{url: "gs://google-charts", part: "bucket", wantErr: errInvalidBucketName},
+		{url: "gs://" + strings.Repeat("a", 64), part: "bucket", wantErr: errInvalidBucketName},
+		{url: "gs://" + strings.Repeat("a", 64) + ".example.com", part: "bucket", wantErr: errInvalidBucketName},
+		{url: "oci://registry.example.com/Team/Charts", part: "path", wantErr: errInvalidRepositoryPath},
+		{url: "oci://registry.example.com", part: "path", wantErr: errInvalidRepositoryPath},
+		{url: "oci://registry.example.com/charts/app:1.0.0", part: "path", wantErr: errRepositoryReference},
+		{url: "oci://registry.example.com/charts/app@sha256:0123", part: "path", wantErr: errRepositoryReference},
+		{url: "oci:///charts", part: "host", wantErr: errMissingRegistryHost},
+		{url: "s3://my-charts/stable"},
+		{url: "file:///srv/charts", part: "scheme", wantErr: errUnsupportedScheme},
+		{url: "ftp://charts.example.com", part: "scheme", wantErr: errUnsupportedScheme},
+		{url: "s4://my-charts/stable", part: "scheme", wantErr: errUnsupportedScheme},
+		{url: "charts.example.com", part: "scheme", wantErr: errUnsupportedScheme},
+	}
+	// A getter plugin providing s3://
+	providers := getter.Providers{{Schemes: []string{"s3"}}}
+
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			err := validateRepoURL(tt.url, providers)
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Errorf("expected no error, got %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			var urlErr *repoURLError
+			if !errors.As(err, &urlErr) {
+				t.Fatalf("expected a *repoURLError, got %T", err)
+			}
+			if urlErr.Part != tt.part {
+				t.Errorf("expected rejected part %q, got %q", tt.part, urlErr.Part)
+			}
+		})
+	}
+}
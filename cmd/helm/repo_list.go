@@ -18,6 +18,7 @@ package main
 
 import (
 	"io"
+	"sort"
 	"strings"
 
 	"github.com/gosuri/uitable"
@@ -43,7 +44,11 @@ func newRepoListCmd(out io.Writer) *cobra.Command {
 				return errors.New("no repositories to show")
 			}
 
-			return outfmt.Write(out, &repoListWriter{f.Repositories})
+			// Sort by name so the output does not depend on the order repositories were added in
+			repos := append([]*repo.Entry(nil), f.Repositories...)
+			sort.SliceStable(repos, func(i, j int) bool { return repos[i].Name < repos[j].Name })
+
+			return outfmt.Write(out, &repoListWriter{repos})
 		},
 	}
 
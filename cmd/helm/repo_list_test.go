@@ -20,6 +20,23 @@ import (
 	"testing"
 )
 
+func TestRepoListSorted(t *testing.T) {
+	tests := []cmdTestCase{{
+		name:   "list repositories sorted by name",
+		cmd:    "repo list --repository-config testdata/repositories-unsorted.yaml",
+		golden: "output/repo-list-sorted.txt",
+	}, {
+		name:   "list repositories sorted by name in json",
+		cmd:    "repo list -o json --repository-config testdata/repositories-unsorted.yaml",
+		golden: "output/repo-list-sorted-json.txt",
+	}, {
+		name:   "list repositories sorted by name in yaml",
+		cmd:    "repo list -o yaml --repository-config testdata/repositories-unsorted.yaml",
+		golden: "output/repo-list-sorted-yaml.txt",
+	}}
+	runTestCmd(t, tests)
+}
+
 func TestRepoListOutputCompletion(t *testing.T) {
 	outputFlagCompletionTest(t, "repo list")
 }
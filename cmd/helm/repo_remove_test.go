@@ -165,3 +165,20 @@ func TestRepoRemoveFileCompletion(t *testing.T) {
 	checkFileCompletion(t, "repo remove", false)
 	checkFileCompletion(t, "repo remove repo1", false)
 }
+
+func TestRepoRemoveCompletionRepetition(t *testing.T) {
+	tests := []cmdTestCase{{
+		name:   "completion for repo remove",
+		cmd:    "__complete repo remove --repository-config testdata/repositories.yaml ''",
+		golden: "output/repo_list_comp.txt",
+	}, {
+		name:   "completion for repo remove repetition",
+		cmd:    "__complete repo remove --repository-config testdata/repositories.yaml firstexample ''",
+		golden: "output/repo_list_repeat_comp.txt",
+	}, {
+		name:   "completion for repo update repetition",
+		cmd:    "__complete repo update --repository-config testdata/repositories.yaml firstexample ''",
+		golden: "output/repo_list_repeat_comp.txt",
+	}}
+	runTestCmd(t, tests)
+}
@@ -0,0 +1,107 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"helm.sh/helm/v3/cmd/helm/require"
+	"helm.sh/helm/v3/pkg/helmpath"
+	"helm.sh/helm/v3/pkg/repo"
+)
+
+type repoRenameOptions struct {
+	oldName   string
+	newName   string
+	repoFile  string
+	repoCache string
+}
+
+func newRepoRenameCmd(out io.Writer) *cobra.Command {
+	o := &repoRenameOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "rename OLD NEW",
+		Short: "rename a chart repository",
+		Long:  "Rename a chart repository, keeping its URL, credentials and cached index.",
+		Args:  require.ExactArgs(2),
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) == 0 {
+				return compListRepos(toComplete, args), cobra.ShellCompDirectiveNoFileComp
+			}
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			o.repoFile = settings.RepositoryConfig
+			o.repoCache = settings.RepositoryCache
+			o.oldName = args[0]
+			o.newName = args[1]
+			return o.run(out)
+		},
+	}
+	return cmd
+}
+
+func (o *repoRenameOptions) run(out io.Writer) error {
+	r, err := repo.LoadFile(o.repoFile)
+	if isNotExist(err) || len(r.Repositories) == 0 {
+		return errors.New("no repositories configured")
+	}
+
+	entry := r.Get(o.oldName)
+	if entry == nil {
+		return errors.Errorf("no repo named %q found", o.oldName)
+	}
+	if r.Has(o.newName) {
+		return errors.Errorf("repository name (%s) already exists", o.newName)
+	}
+
+	entry.Name = o.newName
+	if err := r.WriteFile(o.repoFile, 0644); err != nil {
+		return err
+	}
+
+	if err := renameRepoCache(o.repoCache, o.oldName, o.newName); err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "%q has been renamed to %q\n", o.oldName, o.newName)
+	return nil
+}
+
+// renameRepoCache moves the cached index and chart list of a repository to
+// the file names derived from its new name.
+func renameRepoCache(root, oldName, newName string) error {
+	for _, names := range [][2]string{
+		{helmpath.CacheIndexFile(oldName), helmpath.CacheIndexFile(newName)},
+		{helmpath.CacheChartsFile(oldName), helmpath.CacheChartsFile(newName)},
+	} {
+		src := filepath.Join(root, names[0])
+		if _, err := os.Stat(src); os.IsNotExist(err) {
+			continue
+		}
+		if err := os.Rename(src, filepath.Join(root, names[1])); err != nil {
+			return errors.Wrapf(err, "can't rename cache file %s", src)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"helm.sh/helm/v3/internal/test/ensure"
+	"helm.sh/helm/v3/pkg/repo"
+)
+
+func TestRepoRename(t *testing.T) {
+	rootDir := ensure.TempDir(t)
+	repoFile := filepath.Join(rootDir, "repositories.yaml")
+
+	f := repo.NewFile()
+	f.Add(&repo.Entry{
+		Name:     "old",
+		URL:      "https://charts.example.com",
+		Username: "user",
+		Password: "pass",
+		CAFile:   "ca.pem",
+	}, &repo.Entry{
+		Name: "taken",
+		URL:  "https://other.example.com",
+	})
+	if err := f.WriteFile(repoFile, 0644); err != nil {
+		t.Fatal(err)
+	}
+	cacheIndexFile, cacheChartsFile := createCacheFiles(rootDir, "old")
+
+	b := bytes.NewBuffer(nil)
+	o := &repoRenameOptions{oldName: "old", newName: "new", repoFile: repoFile, repoCache: rootDir}
+	if err := o.run(b); err != nil {
+		t.Fatalf("Error renaming repository: %s", err)
+	}
+	if !strings.Contains(b.String(), `"old" has been renamed to "new"`) {
+		t.Errorf("Unexpected output: %s", b.String())
+	}
+
+	f, err := repo.LoadFile(repoFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.Has("old") {
+		t.Error("old repository name is still present")
+	}
+	renamed := f.Get("new")
+	if renamed == nil {
+		t.Fatal("renamed repository not found")
+	}
+	if renamed.URL != "https://charts.example.com" || renamed.Username != "user" || renamed.Password != "pass" || renamed.CAFile != "ca.pem" {
+		t.Errorf("renamed repository lost its settings: %+v", renamed)
+	}
+
+	testCacheFiles(t, cacheIndexFile, cacheChartsFile, "old")
+	newIndexFile, newChartsFile := filepath.Join(rootDir, "new-index.yaml"), filepath.Join(rootDir, "new-charts.txt")
+	for _, file := range []string{newIndexFile, newChartsFile} {
+		if _, err := os.Stat(file); err != nil {
+			t.Errorf("Expected cache file %s to exist: %s", file, err)
+		}
+	}
+
+	tests := []struct {
+		name             string
+		oldName, newName string
+		expect           string
+	}{
+		{"nonexistent source", "missing", "other", `no repo named "missing" found`},
+		{"destination collision", "new", "taken", "repository name (taken) already exists"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := &repoRenameOptions{oldName: tt.oldName, newName: tt.newName, repoFile: repoFile, repoCache: rootDir}
+			err := o.run(os.Stderr)
+			if err == nil || err.Error() != tt.expect {
+				t.Errorf("Expected error %q, got %v", tt.expect, err)
+			}
+			f, err := repo.LoadFile(repoFile)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !f.Has("new") || !f.Has("taken") || len(f.Repositories) != 2 {
+				t.Errorf("repositories file changed on a failed rename: %+v", f.Repositories)
+			}
+		})
+	}
+}
+
+func TestRepoRenameFileCompletion(t *testing.T) {
+	checkFileCompletion(t, "repo rename", false)
+	checkFileCompletion(t, "repo rename old", false)
+}
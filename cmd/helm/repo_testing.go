@@ -0,0 +1,88 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"helm.sh/helm/v3/cmd/helm/require"
+	"helm.sh/helm/v3/pkg/getter"
+	"helm.sh/helm/v3/pkg/repo"
+)
+
+const repoTestDesc = `
+Fetch the index of a chart repository with its stored URL, credentials and
+TLS settings to check that they still work.
+
+Nothing is written: neither the repositories file nor the cache is changed.
+`
+
+type repoTestOptions struct {
+	name     string
+	repoFile string
+}
+
+func newRepoTestCmd(out io.Writer) *cobra.Command {
+	o := &repoTestOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "test NAME",
+		Short: "check that a chart repository can be reached with its stored credentials",
+		Long:  repoTestDesc,
+		Args:  require.ExactArgs(1),
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) != 0 {
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+			return compListRepos(toComplete, args), cobra.ShellCompDirectiveNoFileComp
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			o.repoFile = settings.RepositoryConfig
+			o.name = args[0]
+			return o.run(out)
+		},
+	}
+	return cmd
+}
+
+func (o *repoTestOptions) run(out io.Writer) error {
+	f, err := repo.LoadFile(o.repoFile)
+	if isNotExist(err) || len(f.Repositories) == 0 {
+		return errors.New("no repositories configured")
+	}
+
+	entry := f.Get(o.name)
+	if entry == nil {
+		return errors.Errorf("no repo named %q found", o.name)
+	}
+
+	r, err := repo.NewChartRepository(entry, getter.All(settings))
+	if err != nil {
+		return err
+	}
+	index, err := r.FetchIndexFile()
+	if err != nil {
+		return errors.Wrapf(err, "%q (%s) could not be reached with its stored settings", o.name, entry.URL)
+	}
+
+	fmt.Fprintf(out, "%q (%s) is reachable, its index lists %d chart(s)\n", o.name, entry.URL, len(index.Entries))
+	return nil
+}
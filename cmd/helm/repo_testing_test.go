@@ -0,0 +1,92 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"helm.sh/helm/v3/internal/test/ensure"
+	"helm.sh/helm/v3/pkg/repo"
+)
+
+func TestRepoTest(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		username, password, ok := r.BasicAuth()
+		if !ok || username != "username" || password != "password" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		http.FileServer(http.Dir("testdata/testserver")).ServeHTTP(w, r)
+	}))
+	defer srv.Close()
+
+	rootDir := ensure.TempDir(t)
+	repoFile := filepath.Join(rootDir, "repositories.yaml")
+
+	f := repo.NewFile()
+	f.Add(&repo.Entry{Name: "good", URL: srv.URL, Username: "username", Password: "password"},
+		&repo.Entry{Name: "rotated", URL: srv.URL, Username: "username", Password: "old"})
+	if err := f.WriteFile(repoFile, 0644); err != nil {
+		t.Fatal(err)
+	}
+	before, err := ioutil.ReadFile(repoFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b := bytes.NewBuffer(nil)
+	o := &repoTestOptions{name: "good", repoFile: repoFile}
+	if err := o.run(b); err != nil {
+		t.Fatalf("Expected the stored credentials to work, got %s", err)
+	}
+	if !strings.Contains(b.String(), `"good" (`+srv.URL+`) is reachable`) {
+		t.Errorf("Unexpected output: %s", b.String())
+	}
+
+	o = &repoTestOptions{name: "rotated", repoFile: repoFile}
+	err = o.run(b)
+	if err == nil {
+		t.Fatal("Expected the wrong credentials to fail")
+	}
+	for _, expect := range []string{`"rotated" (` + srv.URL + `) could not be reached with its stored settings`, "401 Unauthorized"} {
+		if !strings.Contains(err.Error(), expect) {
+			t.Errorf("Expected %q in error %q", expect, err)
+		}
+	}
+
+	o = &repoTestOptions{name: "missing", repoFile: repoFile}
+	if err := o.run(b); err == nil || err.Error() != `no repo named "missing" found` {
+		t.Errorf("Unexpected error for a missing repository: %v", err)
+	}
+
+	after, err := ioutil.ReadFile(repoFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(before, after) {
+		t.Error("repositories file was modified")
+	}
+	if files, _ := filepath.Glob(filepath.Join(rootDir, "*-index.yaml")); len(files) != 0 {
+		t.Errorf("Expected no cache files to be written, got %v", files)
+	}
+}
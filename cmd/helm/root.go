@@ -48,6 +48,7 @@ Environment variables:
 | Name                               | Description                                                                       |
 |------------------------------------|-----------------------------------------------------------------------------------|
 | $HELM_CACHE_HOME                   | set an alternative location for storing cached files.                             |
+| $HELM_COMP_LOG_LEVEL               | set what completion prints to stderr. Values are: off, error, info, debug         |
 | $HELM_CONFIG_HOME                  | set an alternative location for storing Helm configuration.                       |
 | $HELM_DATA_HOME                    | set an alternative location for storing Helm data.                                |
 | $HELM_DEBUG                        | indicate whether or not Helm is running in Debug mode                             |
@@ -60,6 +61,8 @@ Environment variables:
 | $HELM_REGISTRY_CONFIG              | set the path to the registry config file.                                         |
 | $HELM_REPOSITORY_CACHE             | set the path to the repository cache directory                                    |
 | $HELM_REPOSITORY_CONFIG            | set the path to the repositories file.                                            |
+| $HELM_RETRIES                      | set the number of times a failed chart or repository index download is retried.  |
+| $HELM_USER_AGENT                   | set the User-Agent sent with HTTP requests (default "Helm/<version>")             |
 | $KUBECONFIG                        | set an alternative Kubernetes configuration file (default "~/.kube/config")       |
 | $HELM_KUBEAPISERVER                | set the Kubernetes API Server Endpoint for authentication                         |
 | $HELM_KUBECAFILE                   | set the Kubernetes certificate authority file.                                    |
@@ -101,17 +104,20 @@ func newRootCmd(actionConfig *action.Configuration, out io.Writer, args []string
 			// Choose a long enough timeout that the user notices something is not working
 			// but short enough that the user is not made to wait very long
 			to := int64(3)
-			cobra.CompDebugln(fmt.Sprintf("About to call kube client for namespaces with timeout of: %d", to), settings.Debug)
+			compDebugln(fmt.Sprintf("About to call kube client for namespaces with timeout of: %d", to))
 
+			namespaces, err := client.CoreV1().Namespaces().List(context.Background(), metav1.ListOptions{TimeoutSeconds: &to})
+			if err != nil {
+				compErrorln(fmt.Sprintf("Unable to list namespaces: %v", err))
+				return nil, cobra.ShellCompDirectiveDefault
+			}
 			nsNames := []string{}
-			if namespaces, err := client.CoreV1().Namespaces().List(context.Background(), metav1.ListOptions{TimeoutSeconds: &to}); err == nil {
-				for _, ns := range namespaces.Items {
-					if strings.HasPrefix(ns.Name, toComplete) {
-						nsNames = append(nsNames, ns.Name)
-					}
+			for _, ns := range namespaces.Items {
+				if strings.HasPrefix(ns.Name, toComplete) {
+					nsNames = append(nsNames, ns.Name)
 				}
-				return nsNames, cobra.ShellCompDirectiveNoFileComp
 			}
+			return nsNames, cobra.ShellCompDirectiveNoFileComp
 		}
 		return nil, cobra.ShellCompDirectiveDefault
 	})
@@ -122,7 +128,7 @@ func newRootCmd(actionConfig *action.Configuration, out io.Writer, args []string
 
 	// Setup shell completion for the kube-context flag
 	err = cmd.RegisterFlagCompletionFunc("kube-context", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
-		cobra.CompDebugln("About to get the different kube-contexts", settings.Debug)
+		compDebugln("About to get the different kube-contexts")
 
 		loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
 		if len(settings.KubeConfig) > 0 {
@@ -203,6 +209,10 @@ func newRootCmd(actionConfig *action.Configuration, out io.Writer, args []string
 		newChartCmd(actionConfig, out),
 	)
 
+	// Complete values, keyring and TLS file flags with files of the matching types
+	bindFileFlagCompletions(cmd)
+	bindEnumFlagCompletions(cmd)
+
 	// Find and add plugins
 	loadPlugins(cmd, out)
 
@@ -17,11 +17,14 @@ limitations under the License.
 package main
 
 import (
+	"fmt"
+	"io/ioutil"
 	"os"
 	"path/filepath"
 	"testing"
 
 	"helm.sh/helm/v3/internal/test/ensure"
+	"helm.sh/helm/v3/pkg/cli"
 	"helm.sh/helm/v3/pkg/helmpath"
 	"helm.sh/helm/v3/pkg/helmpath/xdg"
 )
@@ -129,3 +132,58 @@ func TestUnknownSubCmd(t *testing.T) {
 // func TestRootFileCompletion(t *testing.T) {
 // 	checkFileCompletion(t, "", false)
 // }
+
+func TestCompletionUsesKubeContext(t *testing.T) {
+	defer resetEnv()()
+
+	// Other tests may leave the namespace of the plugin environment behind.
+	os.Unsetenv("HELM_NAMESPACE")
+	settings = cli.New()
+
+	kubeconfig := filepath.Join(ensure.TempDir(t), "config")
+	err := ioutil.WriteFile(kubeconfig, []byte(`apiVersion: v1
+kind: Config
+current-context: dev
+clusters:
+- name: dev
+  cluster:
+    server: https://dev.example.com
+- name: staging
+  cluster:
+    server: https://staging.example.com
+contexts:
+- name: dev
+  context:
+    cluster: dev
+    namespace: dev-ns
+- name: staging
+  context:
+    cluster: staging
+    namespace: staging-ns
+users: []
+`), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := fmt.Sprintf("__complete --kubeconfig %s --kube-context staging status ''", kubeconfig)
+	if _, _, err := executeActionCommand(cmd); err != nil {
+		t.Fatal(err)
+	}
+
+	// Completion functions build their clients from the settings, which must
+	// reflect the flags given on the line being completed.
+	if settings.KubeContext != "staging" {
+		t.Errorf("Expected kube context %q, got %q", "staging", settings.KubeContext)
+	}
+	config, err := settings.RESTClientGetter().ToRESTConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.Host != "https://staging.example.com" {
+		t.Errorf("Expected the client to use the staging cluster, got %q", config.Host)
+	}
+	if ns := settings.Namespace(); ns != "staging-ns" {
+		t.Errorf("Expected namespace %q, got %q", "staging-ns", ns)
+	}
+}
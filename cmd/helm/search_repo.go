@@ -294,7 +294,7 @@ func compListChartsOfRepo(repoName string, prefix string) []string {
 // Provide dynamic auto-completion for commands that operate on charts (e.g., helm show)
 // When true, the includeFiles argument indicates that completion should include local files (e.g., local charts)
 func compListCharts(toComplete string, includeFiles bool) ([]string, cobra.ShellCompDirective) {
-	cobra.CompDebugln(fmt.Sprintf("compListCharts with toComplete %s", toComplete), settings.Debug)
+	compDebugln(fmt.Sprintf("compListCharts with toComplete %s", toComplete))
 
 	noSpace := false
 	noFile := false
@@ -315,7 +315,7 @@ func compListCharts(toComplete string, includeFiles bool) ([]string, cobra.Shell
 			noSpace = true
 		}
 	}
-	cobra.CompDebugln(fmt.Sprintf("Completions after repos: %v", completions), settings.Debug)
+	compDebugln(fmt.Sprintf("Completions after repos: %v", completions))
 
 	// Now handle completions for url prefixes
 	for _, url := range []string{"https://", "http://", "file://"} {
@@ -331,7 +331,7 @@ func compListCharts(toComplete string, includeFiles bool) ([]string, cobra.Shell
 			noSpace = true
 		}
 	}
-	cobra.CompDebugln(fmt.Sprintf("Completions after urls: %v", completions), settings.Debug)
+	compDebugln(fmt.Sprintf("Completions after urls: %v", completions))
 
 	// Finally, provide file completion if we need to.
 	// We only do this if:
@@ -350,14 +350,14 @@ func compListCharts(toComplete string, includeFiles bool) ([]string, cobra.Shell
 			}
 		}
 	}
-	cobra.CompDebugln(fmt.Sprintf("Completions after files: %v", completions), settings.Debug)
+	compDebugln(fmt.Sprintf("Completions after files: %v", completions))
 
 	// If the user didn't provide any input to completion,
 	// we provide a hint that a path can also be used
 	if includeFiles && len(toComplete) == 0 {
 		completions = append(completions, "./", "/")
 	}
-	cobra.CompDebugln(fmt.Sprintf("Completions after checking empty input: %v", completions), settings.Debug)
+	compDebugln(fmt.Sprintf("Completions after checking empty input: %v", completions))
 
 	directive := cobra.ShellCompDirectiveDefault
 	if noFile {
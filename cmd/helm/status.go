@@ -20,16 +20,21 @@ import (
 	"fmt"
 	"io"
 	"log"
+	"sort"
 	"strings"
 	"time"
 
+	"github.com/gosuri/uitable"
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
+	"sigs.k8s.io/yaml"
 
 	"helm.sh/helm/v3/cmd/helm/require"
 	"helm.sh/helm/v3/pkg/action"
 	"helm.sh/helm/v3/pkg/chartutil"
 	"helm.sh/helm/v3/pkg/cli/output"
 	"helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/releaseutil"
 )
 
 // NOTE: Keep the list of statuses up-to-date with pkg/release/status.go.
@@ -70,7 +75,7 @@ func newStatusCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 			// strip chart metadata from the output
 			rel.Chart = nil
 
-			return outfmt.Write(out, &statusPrinter{rel, false, client.ShowDescription})
+			return outfmt.Write(out, &statusPrinter{rel, false, client.ShowDescription, false})
 		},
 	}
 
@@ -99,6 +104,7 @@ type statusPrinter struct {
 	release         *release.Release
 	debug           bool
 	showDescription bool
+	showResources   bool
 }
 
 func (s statusPrinter) WriteJSON(out io.Writer) error {
@@ -123,6 +129,11 @@ func (s statusPrinter) WriteTable(out io.Writer) error {
 	if s.showDescription {
 		fmt.Fprintf(out, "DESCRIPTION: %s\n", s.release.Info.Description)
 	}
+	if s.showResources {
+		if err := writeResourcesTable(out, s.release); err != nil {
+			return err
+		}
+	}
 
 	executions := executionsByHookEvent(s.release)
 	if tests, ok := executions[release.HookTest]; !ok || len(tests) == 0 {
@@ -179,6 +190,46 @@ func (s statusPrinter) WriteTable(out io.Writer) error {
 	return nil
 }
 
+// writeResourcesTable lists the kind, name and namespace of the resources in
+// the release manifest. Resources without a namespace in the manifest are
+// shown in the release namespace, including cluster-scoped ones.
+func writeResourcesTable(out io.Writer, rel *release.Release) error {
+	manifests := releaseutil.SplitManifests(rel.Manifest)
+	keys := make([]string, 0, len(manifests))
+	for k := range manifests {
+		keys = append(keys, k)
+	}
+	sort.Sort(releaseutil.BySplitManifestsOrder(keys))
+
+	tbl := uitable.New()
+	tbl.AddRow("KIND", "NAME", "NAMESPACE")
+	for _, k := range keys {
+		var head struct {
+			Kind     string `json:"kind"`
+			Metadata struct {
+				Name      string `json:"name"`
+				Namespace string `json:"namespace"`
+			} `json:"metadata"`
+		}
+		if err := yaml.Unmarshal([]byte(manifests[k]), &head); err != nil {
+			return errors.Wrapf(err, "unable to parse the manifest of release %s", rel.Name)
+		}
+		if head.Kind == "" {
+			continue
+		}
+		namespace := head.Metadata.Namespace
+		if namespace == "" {
+			namespace = rel.Namespace
+		}
+		tbl.AddRow(head.Kind, head.Metadata.Name, namespace)
+	}
+	fmt.Fprintln(out, "RESOURCES:")
+	if err := output.EncodeTable(out, tbl); err != nil {
+		return err
+	}
+	return nil
+}
+
 func executionsByHookEvent(rel *release.Release) map[release.HookEvent][]*release.Hook {
 	result := make(map[release.HookEvent][]*release.Hook)
 	for _, h := range rel.Hooks {
@@ -171,3 +171,16 @@ func TestTemplateFileCompletion(t *testing.T) {
 	checkFileCompletion(t, "template myname", true)
 	checkFileCompletion(t, "template myname mychart", false)
 }
+
+func TestTemplateCompletionAfterTerminator(t *testing.T) {
+	tests := []cmdTestCase{{
+		name:   "completion for flags before the end-of-flags marker",
+		cmd:    "__complete template myname mychart --kube-con",
+		golden: "output/template-kube-context-flag-comp.txt",
+	}, {
+		name:   "no flag completion after the end-of-flags marker",
+		cmd:    "__complete template myname mychart -- --kube-con",
+		golden: "output/empty_nofile_comp.txt",
+	}}
+	runTestCmd(t, tests)
+}
@@ -19,19 +19,35 @@ package registry // import "helm.sh/helm/v3/internal/experimental/registry"
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"io/ioutil"
+	"net"
 	"net/http"
+	"os"
+	"path/filepath"
 	"sort"
+	"strings"
+	"sync"
+	"time"
 
-	auth "github.com/deislabs/oras/pkg/auth/docker"
+	"github.com/containerd/containerd/remotes/docker"
+	"github.com/deislabs/oras/pkg/auth"
 	"github.com/deislabs/oras/pkg/content"
 	"github.com/deislabs/oras/pkg/oras"
+	"github.com/docker/cli/cli/config/configfile"
+	"github.com/docker/cli/cli/config/credentials"
+	ctypes "github.com/docker/cli/cli/config/types"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/errdefs"
+	dockerregistry "github.com/docker/docker/registry"
+	"github.com/gofrs/flock"
 	"github.com/gosuri/uitable"
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 	"github.com/pkg/errors"
 
+	"helm.sh/helm/v3/internal/version"
 	"helm.sh/helm/v3/pkg/chart"
 	"helm.sh/helm/v3/pkg/helmpath"
 )
@@ -41,6 +57,15 @@ const (
 	CredentialsFileBasename = "config.json"
 )
 
+var (
+	// ErrInvalidCredentials is wrapped by VerifyLogin errors when the
+	// registry rejects the credentials.
+	ErrInvalidCredentials = errors.New("invalid credentials")
+	// ErrRegistryUnreachable is wrapped by VerifyLogin errors when the
+	// registry cannot be connected to.
+	ErrRegistryUnreachable = errors.New("registry unreachable")
+)
+
 type (
 	// Client works with OCI-compliant registries and local Helm chart cache
 	Client struct {
@@ -52,6 +77,11 @@ type (
 		resolver        *Resolver
 		cache           *Cache
 		columnWidth     uint
+		// credentialsMu serializes credential updates made through this client
+		credentialsMu sync.Mutex
+		// ownCredentials is set when no authorizer was given, in which case
+		// the client reads and writes credentialsFile itself
+		ownCredentials bool
 	}
 )
 
@@ -68,21 +98,28 @@ func NewClient(opts ...ClientOption) (*Client, error) {
 		client.credentialsFile = helmpath.CachePath("registry", CredentialsFileBasename)
 	}
 	if client.authorizer == nil {
-		authClient, err := auth.NewClient(client.credentialsFile)
-		if err != nil {
+		if _, err := client.loadCredentials(); err != nil {
 			return nil, err
 		}
-		client.authorizer = &Authorizer{
-			Client: authClient,
-		}
+		client.ownCredentials = true
 	}
 	if client.resolver == nil {
-		resolver, err := client.authorizer.Resolver(context.Background(), http.DefaultClient, false)
-		if err != nil {
-			return nil, err
-		}
-		client.resolver = &Resolver{
-			Resolver: resolver,
+		httpClient := &http.Client{Transport: newUserAgentTransport(http.DefaultTransport)}
+		if client.ownCredentials {
+			client.resolver = &Resolver{
+				Resolver: docker.NewResolver(docker.ResolverOptions{
+					Credentials: client.credential,
+					Client:      httpClient,
+				}),
+			}
+		} else {
+			resolver, err := client.authorizer.Resolver(context.Background(), httpClient, false)
+			if err != nil {
+				return nil, err
+			}
+			client.resolver = &Resolver{
+				Resolver: resolver,
+			}
 		}
 	}
 	if client.cache == nil {
@@ -105,7 +142,17 @@ func NewClient(opts ...ClientOption) (*Client, error) {
 
 // Login logs into a registry
 func (c *Client) Login(hostname string, username string, password string, insecure bool) error {
-	err := c.authorizer.Login(ctx(c.out, c.debug), hostname, username, password, insecure)
+	unlock, err := c.lockCredentials()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if c.ownCredentials {
+		err = c.login(hostname, username, password, insecure)
+	} else {
+		err = c.authorizer.Login(ctx(c.out, c.debug), hostname, username, password, insecure)
+	}
 	if err != nil {
 		return err
 	}
@@ -115,7 +162,17 @@ func (c *Client) Login(hostname string, username string, password string, insecu
 
 // Logout logs out of a registry
 func (c *Client) Logout(hostname string) error {
-	err := c.authorizer.Logout(ctx(c.out, c.debug), hostname)
+	unlock, err := c.lockCredentials()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if c.ownCredentials {
+		err = c.logout(hostname)
+	} else {
+		err = c.authorizer.Logout(ctx(c.out, c.debug), hostname)
+	}
 	if err != nil {
 		return err
 	}
@@ -123,6 +180,200 @@ func (c *Client) Logout(hostname string) error {
 	return nil
 }
 
+// VerifyLogin checks that the registry accepts the credentials, without
+// storing them. Without a username, password is used as an identity token.
+//
+// The returned error wraps ErrInvalidCredentials when the registry rejects the
+// credentials, and ErrRegistryUnreachable when it cannot be connected to.
+func (c *Client) VerifyLogin(hostname string, username string, password string, insecure bool) error {
+	if _, err := c.authenticate(resolveHostname(hostname), username, password, insecure); err != nil {
+		return verifyLoginError(hostname, err)
+	}
+	fmt.Fprintln(c.out, "Login verified")
+	return nil
+}
+
+// login checks the credentials with the registry and stores them in the
+// credentials file, like the Login of an authorizer does.
+func (c *Client) login(hostname string, username string, password string, insecure bool) error {
+	hostname = resolveHostname(hostname)
+	cred, err := c.authenticate(hostname, username, password, insecure)
+	if err != nil {
+		return err
+	}
+	config, err := c.loadCredentials()
+	if err != nil {
+		return err
+	}
+	return config.GetCredentialsStore(hostname).Store(ctypes.AuthConfig(cred))
+}
+
+// logout removes the credentials for the registry from the credentials file.
+func (c *Client) logout(hostname string) error {
+	hostname = resolveHostname(hostname)
+	config, err := c.loadCredentials()
+	if err != nil {
+		return err
+	}
+	if _, ok := config.AuthConfigs[hostname]; !ok {
+		return auth.ErrNotLoggedIn
+	}
+	return config.GetCredentialsStore(hostname).Erase(hostname)
+}
+
+// authenticate checks the credentials with the registry, sending the Helm
+// user agent. Without a username, password is used as an identity token. The
+// returned credentials are the ones to store, as the registry may exchange
+// them for an identity token.
+func (c *Client) authenticate(hostname string, username string, password string, insecure bool) (types.AuthConfig, error) {
+	cred := types.AuthConfig{
+		Username:      username,
+		ServerAddress: hostname,
+	}
+	if username == "" {
+		cred.IdentityToken = password
+	} else {
+		cred.Password = password
+	}
+
+	opts := dockerregistry.ServiceOptions{}
+	if insecure {
+		opts.InsecureRegistries = []string{hostname}
+	}
+	remote, err := dockerregistry.NewService(opts)
+	if err != nil {
+		return cred, err
+	}
+	_, token, err := remote.Auth(ctx(c.out, c.debug), &cred, version.GetUserAgent())
+	if err != nil {
+		return cred, err
+	}
+	if token != "" {
+		cred.Username = ""
+		cred.Password = ""
+		cred.IdentityToken = token
+	}
+	return cred, nil
+}
+
+// credential returns the credentials stored for the registry. The credentials
+// file is read on every call, so logins made since the client was created,
+// by this process or another, are used.
+func (c *Client) credential(hostname string) (string, string, error) {
+	config, err := c.loadCredentials()
+	if err != nil {
+		return "", "", err
+	}
+	cred, err := config.GetAuthConfig(resolveHostname(hostname))
+	if err != nil {
+		return "", "", err
+	}
+	if cred.IdentityToken != "" {
+		return "", cred.IdentityToken, nil
+	}
+	return cred.Username, cred.Password, nil
+}
+
+// loadCredentials reads the credentials file, which may not exist yet.
+func (c *Client) loadCredentials() (*configfile.ConfigFile, error) {
+	config := configfile.New(c.credentialsFile)
+	f, err := os.Open(c.credentialsFile)
+	if err == nil {
+		defer f.Close()
+		if err := config.LoadFromReader(f); err != nil {
+			return nil, errors.Wrapf(err, "unable to parse credentials file %s", c.credentialsFile)
+		}
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+	if !config.ContainsAuth() {
+		config.CredentialsStore = credentials.DetectDefaultStore(config.CredentialsStore)
+	}
+	return config, nil
+}
+
+// resolveHostname maps the Docker Hub hostnames to the name its credentials
+// are stored under.
+func resolveHostname(hostname string) string {
+	switch hostname {
+	case dockerregistry.IndexHostname, dockerregistry.IndexName, dockerregistry.DefaultV2Registry.Host:
+		return dockerregistry.IndexServer
+	}
+	return hostname
+}
+
+// verifyLoginError tells rejected credentials apart from an unreachable
+// registry in an error returned by the Docker registry client.
+func verifyLoginError(hostname string, err error) error {
+	// Registries using basic auth answer a rejected login with a plain 401,
+	// which the Docker client only reports in the error message.
+	if errdefs.IsUnauthorized(err) || strings.Contains(err.Error(), "status: 401") {
+		return fmt.Errorf("%w for %s: %s", ErrInvalidCredentials, hostname, err)
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return fmt.Errorf("%w: %s: %s", ErrRegistryUnreachable, hostname, err)
+	}
+	return errors.Wrapf(err, "unable to verify login to %s", hostname)
+}
+
+// Hosts returns the sorted hostnames of the registries that the credentials
+// file holds credentials for.
+func (c *Client) Hosts() ([]string, error) {
+	data, err := ioutil.ReadFile(c.credentialsFile)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var config struct {
+		Auths map[string]json.RawMessage `json:"auths"`
+	}
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, errors.Wrapf(err, "unable to parse credentials file %s", c.credentialsFile)
+	}
+	hosts := make([]string, 0, len(config.Auths))
+	for host := range config.Auths {
+		hosts = append(hosts, host)
+	}
+	sort.Strings(hosts)
+	return hosts, nil
+}
+
+// lockCredentials guards the read/modify/write of the credentials file.
+//
+// The mutex protects the in-memory credentials shared by goroutines using this
+// client, while the file lock keeps other processes from writing the file at
+// the same time. The returned function releases both.
+//
+// A client without an authorizer reads the file after it holds the lock, so
+// the updates other processes made since are kept. An authorizer given with
+// ClientOptAuthorizer holds the credentials it loaded when it was created, and
+// may overwrite them.
+func (c *Client) lockCredentials() (func(), error) {
+	c.credentialsMu.Lock()
+
+	// Ensure the file directory exists as it is required for file locking
+	if err := os.MkdirAll(filepath.Dir(c.credentialsFile), 0755); err != nil {
+		c.credentialsMu.Unlock()
+		return nil, err
+	}
+
+	fileLock := flock.New(c.credentialsFile + ".lock")
+	lockCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if _, err := fileLock.TryLockContext(lockCtx, 100*time.Millisecond); err != nil {
+		c.credentialsMu.Unlock()
+		return nil, errors.Wrapf(err, "unable to lock credentials file %s", c.credentialsFile)
+	}
+
+	return func() {
+		fileLock.Unlock()
+		c.credentialsMu.Unlock()
+	}, nil
+}
+
 // PushChart uploads a chart to a registry
 func (c *Client) PushChart(ref *Reference) error {
 	r, err := c.cache.FetchReference(ref)
@@ -19,15 +19,18 @@ package registry
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"io/ioutil"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -94,6 +97,7 @@ func (suite *RegistryClientTestSuite) SetupSuite() {
 			Resolver: resolver,
 		}),
 		ClientOptCache(cache),
+		ClientOptCredentialsFile(credentialsFile),
 	)
 	suite.Nil(err, "no error creating registry client")
 
@@ -145,6 +149,36 @@ func (suite *RegistryClientTestSuite) Test_0_Login() {
 	suite.Nil(err, "no error logging into registry with good credentials, insecure mode")
 }
 
+func (suite *RegistryClientTestSuite) Test_0_LoginConcurrent() {
+	// Both names resolve to the test registry but are stored as distinct hosts
+	_, port, err := net.SplitHostPort(suite.DockerRegistryHost)
+	suite.Nil(err)
+	hosts := []string{suite.DockerRegistryHost, net.JoinHostPort("127.0.0.1", port)}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		for _, host := range hosts {
+			wg.Add(1)
+			go func(host string) {
+				defer wg.Done()
+				err := suite.RegistryClient.Login(host, testUsername, testPassword, false)
+				suite.Nil(err, "no error logging into %s", host)
+			}(host)
+		}
+	}
+	wg.Wait()
+
+	b, err := ioutil.ReadFile(filepath.Join(suite.CacheRootDir, CredentialsFileBasename))
+	suite.Nil(err, "no error reading credentials file")
+	var config struct {
+		Auths map[string]interface{} `json:"auths"`
+	}
+	suite.Nil(json.Unmarshal(b, &config), "credentials file is valid JSON")
+	for _, host := range hosts {
+		suite.Contains(config.Auths, host, "credentials persisted for %s", host)
+	}
+}
+
 func (suite *RegistryClientTestSuite) Test_1_SaveChart() {
 	ref, err := ParseReference(fmt.Sprintf("%s/testrepo/testchart:1.2.3", suite.DockerRegistryHost))
 	suite.Nil(err)
@@ -292,3 +326,107 @@ func initCompromisedRegistryTestServer() string {
 	u, _ := url.Parse(s.URL)
 	return fmt.Sprintf("localhost:%s", u.Port())
 }
+
+func TestLoginKeepsOtherClientsCredentials(t *testing.T) {
+	// A plain HTTP registry that allows anonymous access
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+	port := srv.Listener.Addr().(*net.TCPAddr).Port
+	hostA := fmt.Sprintf("127.0.0.1:%d", port)
+	hostB := fmt.Sprintf("localhost:%d", port)
+
+	tdir, err := ioutil.TempDir("", "helm-registry-credentials-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tdir)
+	credentialsFile := filepath.Join(tdir, CredentialsFileBasename)
+
+	// Both clients load the credentials file before either logs in, as two
+	// helm processes would
+	var clients []*Client
+	for i := 0; i < 2; i++ {
+		cache, err := NewCache(CacheOptRoot(filepath.Join(tdir, fmt.Sprintf("cache%d", i))))
+		if err != nil {
+			t.Fatal(err)
+		}
+		client, err := NewClient(ClientOptCredentialsFile(credentialsFile), ClientOptCache(cache))
+		if err != nil {
+			t.Fatal(err)
+		}
+		clients = append(clients, client)
+	}
+
+	if err := clients[0].Login(hostA, "", "", true); err != nil {
+		t.Fatal(err)
+	}
+	if err := clients[1].Login(hostB, "", "", true); err != nil {
+		t.Fatal(err)
+	}
+
+	hosts, err := clients[0].Hosts()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hosts) != 2 {
+		t.Errorf("Expected credentials for %s and %s, got %v", hostA, hostB, hosts)
+	}
+}
+
+func TestLoginUserAgent(t *testing.T) {
+	var (
+		mu         sync.Mutex
+		userAgents []string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		userAgents = append(userAgents, r.UserAgent())
+		mu.Unlock()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+	host := srv.Listener.Addr().String()
+
+	tdir, err := ioutil.TempDir("", "helm-registry-user-agent-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tdir)
+	cache, err := NewCache(CacheOptRoot(filepath.Join(tdir, "cache")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := NewClient(ClientOptCredentialsFile(filepath.Join(tdir, CredentialsFileBasename)), ClientOptCache(cache))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := client.VerifyLogin(host, testUsername, testPassword, true); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.Login(host, testUsername, testPassword, true); err != nil {
+		t.Fatal(err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(userAgents) == 0 {
+		t.Fatal("Expected requests to the registry")
+	}
+	for _, ua := range userAgents {
+		if !strings.HasPrefix(ua, "Helm/") {
+			t.Errorf("Expected the Helm user agent, got %q", ua)
+		}
+	}
+
+	// The resolver uses the credentials stored by the login
+	username, password, err := client.credential(host)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if username != testUsername || password != testPassword {
+		t.Errorf("Expected the stored credentials %s/%s, got %s/%s", testUsername, testPassword, username, password)
+	}
+}
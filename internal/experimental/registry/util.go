@@ -20,11 +20,14 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"net/http"
 	"time"
 
 	orascontext "github.com/deislabs/oras/pkg/context"
 	units "github.com/docker/go-units"
 	"github.com/sirupsen/logrus"
+
+	"helm.sh/helm/v3/internal/version"
 )
 
 // byteCountBinary produces a human-readable file size
@@ -64,3 +67,19 @@ func ctx(out io.Writer, debug bool) context.Context {
 	orascontext.GetLogger(ctx).Logger.SetLevel(logrus.DebugLevel)
 	return ctx
 }
+
+// userAgentTransport sets the Helm user agent on every request, replacing the
+// one set by the underlying registry libraries.
+type userAgentTransport struct {
+	base http.RoundTripper
+}
+
+func newUserAgentTransport(base http.RoundTripper) http.RoundTripper {
+	return &userAgentTransport{base: base}
+}
+
+func (t *userAgentTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Header.Set("User-Agent", version.GetUserAgent())
+	return t.base.RoundTrip(req)
+}
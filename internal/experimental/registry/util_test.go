@@ -0,0 +1,60 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registry
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"helm.sh/helm/v3/internal/version"
+)
+
+func TestUserAgentTransport(t *testing.T) {
+	var userAgent string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userAgent = r.UserAgent()
+	}))
+	defer srv.Close()
+
+	client := &http.Client{Transport: newUserAgentTransport(http.DefaultTransport)}
+	get := func() {
+		req, err := http.NewRequest(http.MethodGet, srv.URL, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("User-Agent", "containerd/1.4.4")
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+	}
+
+	get()
+	assert.True(t, strings.HasPrefix(userAgent, "Helm/"), "expected a Helm user agent, got %q", userAgent)
+	assert.Contains(t, userAgent, strings.TrimPrefix(version.GetVersion(), "v"))
+
+	defer os.Unsetenv(version.UserAgentEnvVar)
+	os.Setenv(version.UserAgentEnvVar, "custom-helm/1.0")
+	get()
+	assert.Equal(t, "custom-helm/1.0", userAgent)
+}
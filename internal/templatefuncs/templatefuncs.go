@@ -0,0 +1,27 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package templatefuncs gives Helm's own packages the functions of chart
+// templates, so they can parse templates outside of an engine without the
+// function map being part of the public engine API.
+package templatefuncs
+
+import "text/template"
+
+// FuncMap returns the functions available to chart templates. The late-bound
+// functions, such as include and tpl, are placeholders. It is set when the
+// engine package is initialized.
+var FuncMap func() template.FuncMap
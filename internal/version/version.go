@@ -18,6 +18,7 @@ package version // import "helm.sh/helm/v3/internal/version"
 
 import (
 	"flag"
+	"os"
 	"runtime"
 	"strings"
 )
@@ -59,8 +60,15 @@ func GetVersion() string {
 	return version + "+" + metadata
 }
 
+// UserAgentEnvVar is the environment variable that overrides the user agent
+// Helm sends, so custom builds can identify themselves to servers.
+const UserAgentEnvVar = "HELM_USER_AGENT"
+
 // GetUserAgent returns a user agent for user with an HTTP client
 func GetUserAgent() string {
+	if ua := os.Getenv(UserAgentEnvVar); ua != "" {
+		return ua
+	}
 	return "Helm/" + strings.TrimPrefix(GetVersion(), "v")
 }
 
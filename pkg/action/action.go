@@ -95,6 +95,13 @@ type Configuration struct {
 	Capabilities *chartutil.Capabilities
 
 	Log func(string, ...interface{})
+
+	// Audit receives an event for every install, rollback and uninstall. It
+	// may be nil.
+	Audit AuditSink
+
+	// AuditUser is the user recorded in audit events.
+	AuditUser string
 }
 
 // renderResources renders the templates in a chart
@@ -246,6 +253,32 @@ func (cfg *Configuration) getCapabilities() (*chartutil.Capabilities, error) {
 	}
 	// force a discovery cache invalidation to always fetch the latest server version/capabilities.
 	dc.Invalidate()
+	caps, err := capabilitiesFromDiscovery(dc, cfg.Log)
+	if err != nil {
+		return nil, err
+	}
+	cfg.Capabilities = caps
+	return cfg.Capabilities, nil
+}
+
+// DiscoverCapabilities returns the Kubernetes version and API versions, those
+// of installed CRDs included, that the cluster behind dc reports. When dc is
+// nil or the cluster cannot be reached it returns chartutil.DefaultCapabilities,
+// so that charts can still be rendered offline.
+func DiscoverCapabilities(dc discovery.DiscoveryInterface) *chartutil.Capabilities {
+	if dc == nil {
+		return chartutil.DefaultCapabilities.Copy()
+	}
+	caps, err := capabilitiesFromDiscovery(dc, func(string, ...interface{}) {})
+	if err != nil {
+		return chartutil.DefaultCapabilities.Copy()
+	}
+	return caps
+}
+
+// capabilitiesFromDiscovery asks the discovery client for the server version
+// and the API versions it serves.
+func capabilitiesFromDiscovery(dc discovery.DiscoveryInterface, log func(string, ...interface{})) (*chartutil.Capabilities, error) {
 	kubeVersion, err := dc.ServerVersion()
 	if err != nil {
 		return nil, errors.Wrap(err, "could not get server version from Kubernetes")
@@ -258,22 +291,21 @@ func (cfg *Configuration) getCapabilities() (*chartutil.Capabilities, error) {
 	apiVersions, err := GetVersionSet(dc)
 	if err != nil {
 		if discovery.IsGroupDiscoveryFailedError(err) {
-			cfg.Log("WARNING: The Kubernetes server has an orphaned API service. Server reports: %s", err)
-			cfg.Log("WARNING: To fix this, kubectl delete apiservice <service-name>")
+			log("WARNING: The Kubernetes server has an orphaned API service. Server reports: %s", err)
+			log("WARNING: To fix this, kubectl delete apiservice <service-name>")
 		} else {
 			return nil, errors.Wrap(err, "could not get apiVersions from Kubernetes")
 		}
 	}
 
-	cfg.Capabilities = &chartutil.Capabilities{
+	return &chartutil.Capabilities{
 		APIVersions: apiVersions,
 		KubeVersion: chartutil.KubeVersion{
 			Version: kubeVersion.GitVersion,
 			Major:   kubeVersion.Major,
 			Minor:   kubeVersion.Minor,
 		},
-	}
-	return cfg.Capabilities, nil
+	}, nil
 }
 
 // KubernetesClientSet creates a new kubernetes ClientSet based on the configuration
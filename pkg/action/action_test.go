@@ -25,7 +25,12 @@ import (
 	"testing"
 
 	dockerauth "github.com/deislabs/oras/pkg/auth/docker"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/version"
+	fakediscovery "k8s.io/client-go/discovery/fake"
 	fakeclientset "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
 
 	"helm.sh/helm/v3/internal/experimental/registry"
 	"helm.sh/helm/v3/pkg/chart"
@@ -319,3 +324,49 @@ func TestGetVersionSet(t *testing.T) {
 		t.Error("Non-existent version is reported found.")
 	}
 }
+
+func TestDiscoverCapabilities(t *testing.T) {
+	dc := &fakediscovery.FakeDiscovery{
+		Fake: &k8stesting.Fake{
+			Resources: []*metav1.APIResourceList{
+				{GroupVersion: "v1", APIResources: []metav1.APIResource{{Name: "pods", Kind: "Pod"}}},
+				{GroupVersion: "monitoring.coreos.com/v1", APIResources: []metav1.APIResource{{Name: "servicemonitors", Kind: "ServiceMonitor"}}},
+			},
+		},
+		FakedServerVersion: &version.Info{GitVersion: "v1.19.4", Major: "1", Minor: "19"},
+	}
+
+	caps := DiscoverCapabilities(dc)
+	if caps.KubeVersion.Version != "v1.19.4" || caps.KubeVersion.Major != "1" || caps.KubeVersion.Minor != "19" {
+		t.Errorf("Expected the server version v1.19.4, got %+v", caps.KubeVersion)
+	}
+	for _, v := range []string{"v1", "v1/Pod", "monitoring.coreos.com/v1", "monitoring.coreos.com/v1/ServiceMonitor"} {
+		if !caps.APIVersions.Has(v) {
+			t.Errorf("Expected %s in the API versions, got %v", v, caps.APIVersions)
+		}
+	}
+}
+
+// unreachableDiscovery is a discovery client for a cluster that cannot be
+// reached.
+type unreachableDiscovery struct {
+	fakediscovery.FakeDiscovery
+}
+
+func (unreachableDiscovery) ServerVersion() (*version.Info, error) {
+	return nil, errors.New("connection refused")
+}
+
+func TestDiscoverCapabilitiesOffline(t *testing.T) {
+	for _, caps := range []*chartutil.Capabilities{
+		DiscoverCapabilities(nil),
+		DiscoverCapabilities(&unreachableDiscovery{}),
+	} {
+		if caps.KubeVersion != chartutil.DefaultCapabilities.KubeVersion {
+			t.Errorf("Expected the default kube version, got %+v", caps.KubeVersion)
+		}
+		if len(caps.APIVersions) != len(chartutil.DefaultCapabilities.APIVersions) {
+			t.Errorf("Expected the default API versions, got %v", caps.APIVersions)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"time"
+)
+
+// AuditAction is the operation recorded by an AuditEvent.
+type AuditAction string
+
+const (
+	// AuditInstall records an install.
+	AuditInstall AuditAction = "install"
+	// AuditRollback records a rollback.
+	AuditRollback AuditAction = "rollback"
+	// AuditUninstall records an uninstall.
+	AuditUninstall AuditAction = "uninstall"
+)
+
+// AuditOutcome is the result recorded by an AuditEvent.
+type AuditOutcome string
+
+const (
+	// AuditSucceeded means the operation completed.
+	AuditSucceeded AuditOutcome = "succeeded"
+	// AuditFailed means the operation returned an error.
+	AuditFailed AuditOutcome = "failed"
+)
+
+// AuditEvent describes an operation that changed a release in the cluster.
+type AuditEvent struct {
+	Action    AuditAction
+	Release   string
+	Namespace string
+	// User is the Configuration's AuditUser.
+	User    string
+	Outcome AuditOutcome
+	// Error is the error the operation failed with, if any.
+	Error string
+	Time  time.Time
+}
+
+// AuditSink receives an AuditEvent for every install, rollback and uninstall
+// that is not a dry run. Implementations should not block, since events are
+// sent before the action returns.
+type AuditSink interface {
+	Audit(AuditEvent)
+}
+
+// audit sends an event to the configured AuditSink, if there is one.
+func (cfg *Configuration) audit(action AuditAction, name, namespace string, err error) {
+	if cfg.Audit == nil {
+		return
+	}
+	e := AuditEvent{
+		Action:    action,
+		Release:   name,
+		Namespace: namespace,
+		User:      cfg.AuditUser,
+		Outcome:   AuditSucceeded,
+		Time:      cfg.Now().Time,
+	}
+	if err != nil {
+		e.Outcome = AuditFailed
+		e.Error = err.Error()
+	}
+	cfg.Audit.Audit(e)
+}
@@ -28,18 +28,7 @@ import (
 
 // execHook executes all of the hooks for the given hook event.
 func (cfg *Configuration) execHook(rl *release.Release, hook release.HookEvent, timeout time.Duration) error {
-	executingHooks := []*release.Hook{}
-
-	for _, h := range rl.Hooks {
-		for _, e := range h.Events {
-			if e == hook {
-				executingHooks = append(executingHooks, h)
-			}
-		}
-	}
-
-	// hooke are pre-ordered by kind, so keep order stable
-	sort.Stable(hookByWeight(executingHooks))
+	executingHooks := HooksForEvent(rl.Hooks, hook)
 
 	for _, h := range executingHooks {
 		// Set default delete policy to before-hook-creation
@@ -119,6 +108,24 @@ func (x hookByWeight) Less(i, j int) bool {
 	return x[i].Weight < x[j].Weight
 }
 
+// HooksForEvent returns the hooks that run for the given event, in the order
+// Helm runs them: by weight, then by name. Hooks of the same weight and name
+// keep their order, which is the order of their kinds for hooks of a release.
+func HooksForEvent(hooks []*release.Hook, event release.HookEvent) []*release.Hook {
+	selected := []*release.Hook{}
+	for _, h := range hooks {
+		for _, e := range h.Events {
+			if e == event {
+				selected = append(selected, h)
+			}
+		}
+	}
+
+	// hooks are pre-ordered by kind, so keep order stable
+	sort.Stable(hookByWeight(selected))
+	return selected
+}
+
 // deleteHookByPolicy deletes a hook if the hook policy instructs it to
 func (cfg *Configuration) deleteHookByPolicy(h *release.Hook, policy release.HookDeletePolicy) error {
 	// Never delete CustomResourceDefinitions; this could cause lots of
@@ -93,6 +93,8 @@ type Install struct {
 	SubNotes                 bool
 	DisableOpenAPIValidation bool
 	IncludeCRDs              bool
+	// SkipSchemaValidation disables validation of the values against the chart's values.schema.json
+	SkipSchemaValidation bool
 	// KubeVersion allows specifying a custom kubernetes version to use and
 	// APIVersions allows a manual set of supported API Versions to be passed
 	// (for things like templating). These are ignored if ClientOnly is false
@@ -171,10 +173,31 @@ func (i *Install) installCRDs(crds []chart.CRD) error {
 	return nil
 }
 
+// Hooks renders the chart as a dry run of the installation would, and returns
+// the hooks that would run for the given event in the order they would run.
+// The Install itself is not changed.
+func (i *Install) Hooks(chrt *chart.Chart, vals map[string]interface{}, event release.HookEvent) ([]*release.Hook, error) {
+	dryRun := *i
+	dryRun.DryRun = true
+	rel, err := dryRun.Run(chrt, vals)
+	if err != nil {
+		return nil, err
+	}
+	return HooksForEvent(rel.Hooks, event), nil
+}
+
 // Run executes the installation
 //
 // If DryRun is set to true, this will prepare the release, but not install it
 func (i *Install) Run(chrt *chart.Chart, vals map[string]interface{}) (*release.Release, error) {
+	rel, err := i.run(chrt, vals)
+	if !i.DryRun && !i.ClientOnly {
+		i.cfg.audit(AuditInstall, i.ReleaseName, i.Namespace, err)
+	}
+	return rel, err
+}
+
+func (i *Install) run(chrt *chart.Chart, vals map[string]interface{}) (*release.Release, error) {
 	// Check reachability of cluster unless in client-only mode (e.g. `helm template` without `--validate`)
 	if !i.ClientOnly {
 		if err := i.cfg.KubeClient.IsReachable(); err != nil {
@@ -236,7 +259,7 @@ func (i *Install) Run(chrt *chart.Chart, vals map[string]interface{}) (*release.
 		IsInstall: !isUpgrade,
 		IsUpgrade: isUpgrade,
 	}
-	valuesToRender, err := chartutil.ToRenderValues(chrt, vals, options, caps)
+	valuesToRender, err := chartutil.ToRenderValuesWithSchemaValidation(chrt, vals, options, caps, i.SkipSchemaValidation)
 	if err != nil {
 		return nil, err
 	}
@@ -77,6 +77,43 @@ func TestInstallRelease(t *testing.T) {
 	is.Equal(rel.Info.Description, "Install complete")
 }
 
+// auditRecorder is an AuditSink that keeps the events it receives.
+type auditRecorder struct {
+	events []AuditEvent
+}
+
+func (r *auditRecorder) Audit(e AuditEvent) {
+	r.events = append(r.events, e)
+}
+
+func TestInstallAudit(t *testing.T) {
+	is := assert.New(t)
+	instAction := installAction(t)
+	recorder := &auditRecorder{}
+	instAction.cfg.Audit = recorder
+	instAction.cfg.AuditUser = "alice"
+
+	if _, err := instAction.Run(buildChart(), map[string]interface{}{}); err != nil {
+		t.Fatalf("Failed install: %s", err)
+	}
+	is.Len(recorder.events, 1)
+	e := recorder.events[0]
+	is.Equal(AuditInstall, e.Action)
+	is.Equal("test-install-release", e.Release)
+	is.Equal("spaced", e.Namespace)
+	is.Equal("alice", e.User)
+	is.Equal(AuditSucceeded, e.Outcome)
+	is.Empty(e.Error)
+	is.False(e.Time.IsZero())
+
+	instAction.DryRun = true
+	instAction.ReleaseName = "dry-run"
+	if _, err := instAction.Run(buildChart(), map[string]interface{}{}); err != nil {
+		t.Fatalf("Failed dry-run install: %s", err)
+	}
+	is.Len(recorder.events, 1, "Expected dry runs not to be audited")
+}
+
 func TestInstallReleaseWithValues(t *testing.T) {
 	is := assert.New(t)
 	instAction := installAction(t)
@@ -668,3 +705,77 @@ func TestNameAndChartGenerateName(t *testing.T) {
 		})
 	}
 }
+
+func TestInstallRelease_SchemaValidation(t *testing.T) {
+	is := assert.New(t)
+	schema := []byte(`{
+  "$schema": "http://json-schema.org/draft-07/schema#",
+  "type": "object",
+  "required": ["replicas"],
+  "properties": {
+    "replicas": {"type": "integer", "minimum": 1},
+    "image": {"type": "string"}
+  }
+}`)
+	withSchema := func(opts *chartOptions) { opts.Schema = schema }
+
+	instAction := installAction(t)
+	_, err := instAction.Run(buildChart(withSchema), map[string]interface{}{"replicas": 0, "image": 5})
+	is.Error(err)
+	is.Contains(err.Error(), "values don't meet the specifications of the schema(s)")
+	is.Contains(err.Error(), "replicas: Must be greater than or equal to 1")
+	is.Contains(err.Error(), "image: Invalid type. Expected: string, given: integer")
+	_, err = instAction.cfg.Releases.Get(instAction.ReleaseName, 1)
+	is.Error(err, "expected no release to be recorded")
+
+	instAction = installAction(t)
+	res, err := instAction.Run(buildChart(withSchema), map[string]interface{}{"replicas": 2, "image": "nginx"})
+	is.NoError(err)
+	is.Equal(res.Info.Status, release.StatusDeployed)
+
+	instAction = installAction(t)
+	instAction.SkipSchemaValidation = true
+	res, err = instAction.Run(buildChart(withSchema), map[string]interface{}{"replicas": 0})
+	is.NoError(err)
+	is.Equal(res.Info.Status, release.StatusDeployed)
+}
+
+func TestInstallHooks(t *testing.T) {
+	is := assert.New(t)
+	hook := func(name, events, weight string) *chart.File {
+		return &chart.File{
+			Name: "templates/" + name + ".yaml",
+			Data: []byte(fmt.Sprintf(`apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: %s
+  annotations:
+    "helm.sh/hook": %s
+    "helm.sh/hook-weight": "%s"
+data:
+  name: value
+`, name, events, weight)),
+		}
+	}
+	ch := buildChart()
+	ch.Templates = append(ch.Templates,
+		hook("migrate", "pre-install", "5"),
+		hook("backup", "pre-install,pre-upgrade", "5"),
+		hook("secrets", "pre-install", "-1"),
+		hook("notify", "post-install", "0"),
+	)
+
+	instAction := installAction(t)
+	hooks, err := instAction.Hooks(ch, map[string]interface{}{}, release.HookPreInstall)
+	is.NoError(err)
+
+	var names []string
+	for _, h := range hooks {
+		names = append(names, h.Name)
+	}
+	is.Equal([]string{"secrets", "backup", "migrate"}, names)
+	is.False(instAction.DryRun, "Expected the install action to be left unchanged")
+
+	_, err = instAction.cfg.Releases.Get(instAction.ReleaseName, 1)
+	is.Error(err, "Expected no release to be stored")
+}
@@ -21,7 +21,9 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 
+	"github.com/mitchellh/copystructure"
 	"github.com/pkg/errors"
 
 	"helm.sh/helm/v3/pkg/chartutil"
@@ -36,6 +38,15 @@ type Lint struct {
 	Strict        bool
 	Namespace     string
 	WithSubcharts bool
+	// ReleaseName is the release name the templates are rendered with
+	ReleaseName string
+	// KubeVersion is the Kubernetes version the templates are rendered with
+	KubeVersion *chartutil.KubeVersion
+	// Capabilities are the capabilities the templates are rendered with, see
+	// DiscoverCapabilities. KubeVersion takes precedence over their version.
+	Capabilities *chartutil.Capabilities
+	// CheckOverrides warns about values passed to Run that the chart does not use
+	CheckOverrides bool
 }
 
 // LintResult is the result of Lint
@@ -43,6 +54,8 @@ type LintResult struct {
 	TotalChartsLinted int
 	Messages          []support.Message
 	Errors            []error
+	// Capabilities are the capabilities the chart templates were rendered with
+	Capabilities *chartutil.Capabilities
 }
 
 // NewLint creates a new Lint object with the given configuration.
@@ -58,13 +71,21 @@ func (l *Lint) Run(paths []string, vals map[string]interface{}) *LintResult {
 	}
 	result := &LintResult{}
 	for _, path := range paths {
-		linter, err := lintChart(path, vals, l.Namespace, l.Strict)
+		linter, err := lintChart(path, vals, l.Namespace, l.Strict, support.Linter{
+			ReleaseName:    l.ReleaseName,
+			KubeVersion:    l.KubeVersion,
+			Capabilities:   l.Capabilities,
+			CheckOverrides: l.CheckOverrides,
+		})
 		if err != nil {
 			result.Errors = append(result.Errors, err)
 			continue
 		}
 
 		result.Messages = append(result.Messages, linter.Messages...)
+		if linter.Capabilities != nil {
+			result.Capabilities = linter.Capabilities
+		}
 		result.TotalChartsLinted++
 		for _, msg := range linter.Messages {
 			if msg.Severity >= lowestTolerance {
@@ -75,7 +96,49 @@ func (l *Lint) Run(paths []string, vals map[string]interface{}) *LintResult {
 	return result
 }
 
-func lintChart(path string, vals map[string]interface{}, namespace string, strict bool) (support.Linter, error) {
+// RunParallel lints the charts at paths with at most workers charts linted at
+// a time, and returns the result of each chart in the order of paths.
+//
+// Every chart is linted with its own copy of vals. Set Capabilities, see
+// DiscoverCapabilities, to discover the cluster once for all the charts.
+func (l *Lint) RunParallel(paths []string, vals map[string]interface{}, workers int) []*LintResult {
+	if workers < 1 {
+		workers = 1
+	}
+	results := make([]*LintResult, len(paths))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers && w < len(paths); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i] = l.runCopy(paths[i], vals)
+			}
+		}()
+	}
+	for i := range paths {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	return results
+}
+
+// runCopy lints the chart at path with a deep copy of vals, since the rules
+// may modify nested tables of the values they are given.
+func (l *Lint) runCopy(path string, vals map[string]interface{}) *LintResult {
+	v, err := copystructure.Copy(vals)
+	if err != nil {
+		return &LintResult{Errors: []error{errors.Wrap(err, "unable to copy values")}}
+	}
+	valsCopy, _ := v.(map[string]interface{})
+	return l.Run([]string{path}, valsCopy)
+}
+
+// lintChart lints the chart at path, which may be an archive, with the options
+// set on config.
+func lintChart(path string, vals map[string]interface{}, namespace string, strict bool, config support.Linter) (support.Linter, error) {
 	var chartPath string
 	linter := support.Linter{}
 
@@ -114,5 +177,6 @@ func lintChart(path string, vals map[string]interface{}, namespace string, stric
 		return linter, errors.Wrap(err, "unable to check Chart.yaml file in chart")
 	}
 
-	return lint.All(chartPath, vals, namespace, strict), nil
+	config.ChartDir = chartPath
+	return lint.Run(config, vals, namespace, strict), nil
 }
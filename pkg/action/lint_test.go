@@ -17,15 +17,24 @@ limitations under the License.
 package action
 
 import (
+	"io/ioutil"
+	"os"
+	"strings"
 	"testing"
+
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/lint/support"
 )
 
 var (
 	values                  = make(map[string]interface{})
 	namespace               = "testNamespace"
 	strict                  = false
-	chart1MultipleChartLint = "testdata/charts/multiplecharts-lint-chart-1"
-	chart2MultipleChartLint = "testdata/charts/multiplecharts-lint-chart-2"
+	chart1MultipleChartLint = "testdata/charts/multiplecharts-lint-chart-with-kind-1"
+	chart2MultipleChartLint = "testdata/charts/multiplecharts-lint-chart-with-kind-2"
+	chart1MissingKindLint   = "testdata/charts/multiplecharts-lint-chart-1"
+	chart2MissingKindLint   = "testdata/charts/multiplecharts-lint-chart-2"
 	corruptedTgzChart       = "testdata/charts/corrupted-compressed-chart.tgz"
 	chartWithNoTemplatesDir = "testdata/charts/chart-with-no-templates-dir"
 )
@@ -78,7 +87,7 @@ func TestLintChart(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			_, err := lintChart(tt.chartPath, map[string]interface{}{}, namespace, strict)
+			_, err := lintChart(tt.chartPath, map[string]interface{}{}, namespace, strict, support.Linter{})
 			switch {
 			case err != nil && !tt.err:
 				t.Errorf("%s", err)
@@ -131,6 +140,20 @@ func TestLint_MultipleCharts(t *testing.T) {
 	}
 }
 
+func TestLint_MissingKind(t *testing.T) {
+	testCharts := []string{chart1MissingKindLint, chart2MissingKindLint}
+	testLint := NewLint()
+	result := testLint.Run(testCharts, values)
+	if len(result.Errors) != len(testCharts) {
+		t.Fatalf("expected one error per chart, got %v", result.Errors)
+	}
+	for _, err := range result.Errors {
+		if !strings.Contains(err.Error(), "is missing kind") {
+			t.Errorf("expected a missing kind error, got %s", err)
+		}
+	}
+}
+
 func TestLint_EmptyResultErrors(t *testing.T) {
 	testCharts := []string{chart2MultipleChartLint}
 	testLint := NewLint()
@@ -158,3 +181,80 @@ func TestLint_ChartWithWarnings(t *testing.T) {
 		}
 	})
 }
+
+func TestLint_Capabilities(t *testing.T) {
+	testLint := NewLint()
+	result := testLint.Run([]string{chart1MultipleChartLint}, values)
+	if result.Capabilities == nil {
+		t.Fatal("expected the capabilities used for rendering to be reported")
+	}
+	if v := result.Capabilities.KubeVersion.Version; v != chartutil.DefaultCapabilities.KubeVersion.Version {
+		t.Errorf("expected KubeVersion %s, got %s", chartutil.DefaultCapabilities.KubeVersion.Version, v)
+	}
+	if !result.Capabilities.APIVersions.Has("apps/v1") {
+		t.Error("expected apps/v1 to be among the API versions")
+	}
+}
+
+func TestLint_DiscoveredCapabilities(t *testing.T) {
+	testLint := NewLint()
+	testLint.Capabilities = &chartutil.Capabilities{
+		KubeVersion: chartutil.KubeVersion{Version: "v1.19.4", Major: "1", Minor: "19"},
+		APIVersions: chartutil.VersionSet{"v1", "monitoring.coreos.com/v1"},
+	}
+	result := testLint.Run([]string{chart1MultipleChartLint}, values)
+	if result.Capabilities == nil {
+		t.Fatal("expected the capabilities used for rendering to be reported")
+	}
+	if v := result.Capabilities.KubeVersion.Version; v != "v1.19.4" {
+		t.Errorf("expected KubeVersion v1.19.4, got %s", v)
+	}
+	if !result.Capabilities.APIVersions.Has("monitoring.coreos.com/v1") {
+		t.Error("expected monitoring.coreos.com/v1 to be among the API versions")
+	}
+}
+
+func TestLint_RunParallel(t *testing.T) {
+	testLint := NewLint()
+	paths := []string{chart1MultipleChartLint, chart2MultipleChartLint, corruptedTgzChart}
+	results := testLint.RunParallel(paths, map[string]interface{}{"nested": map[string]interface{}{"key": "value"}}, 2)
+	if len(results) != len(paths) {
+		t.Fatalf("expected %d results, got %d", len(paths), len(results))
+	}
+	for i, path := range paths[:2] {
+		if results[i].TotalChartsLinted != 1 || len(results[i].Errors) != 0 {
+			t.Errorf("expected %s to be linted without errors, got %v", path, results[i].Errors)
+		}
+	}
+	if len(results[2].Errors) == 0 {
+		t.Errorf("expected an error for %s", corruptedTgzChart)
+	}
+}
+
+func TestLint_ChartArchive(t *testing.T) {
+	dir, err := ioutil.TempDir("", "helm-lint-archive")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	archive, err := chartutil.Save(&chart.Chart{
+		Metadata: &chart.Metadata{APIVersion: "v2", Name: "archived", Version: "0.1.0"},
+		Templates: []*chart.File{{
+			Name: "templates/baddeployment.yaml",
+			Data: []byte("apiVersion: apps/v1beta1\nkind: Deployment\nmetadata:\n  name: baddep\nspec: {selector: {matchLabels: {foo: bar}}}"),
+		}},
+	}, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The template rules run against the contents of the archive
+	result := NewLint().Run([]string{archive}, values)
+	for _, msg := range result.Messages {
+		if msg.Severity == support.WarningSev && strings.Contains(msg.Err.Error(), "apps/v1beta1") {
+			return
+		}
+	}
+	t.Errorf("expected a deprecated API warning, got %v", result.Messages)
+}
@@ -27,8 +27,10 @@ import (
 	"github.com/pkg/errors"
 	"golang.org/x/term"
 
+	"helm.sh/helm/v3/pkg/chart"
 	"helm.sh/helm/v3/pkg/chart/loader"
 	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/engine"
 	"helm.sh/helm/v3/pkg/provenance"
 )
 
@@ -44,6 +46,9 @@ type Package struct {
 	AppVersion       string
 	Destination      string
 	DependencyUpdate bool
+	// ValidateTemplates renders the chart with its default values before
+	// packaging it and fails if any template cannot be rendered.
+	ValidateTemplates bool
 
 	RepositoryConfig string
 	RepositoryCache  string
@@ -80,6 +85,12 @@ func (p *Package) Run(path string, vals map[string]interface{}) (string, error)
 		}
 	}
 
+	if p.ValidateTemplates {
+		if err := validateTemplates(ch, vals); err != nil {
+			return "", err
+		}
+	}
+
 	var dest string
 	if p.Destination == "." {
 		// Save to the current working directory.
@@ -104,6 +115,25 @@ func (p *Package) Run(path string, vals map[string]interface{}) (string, error)
 	return name, err
 }
 
+// validateTemplates renders every template of the chart to catch parse errors and
+// missing functions before the chart is published.
+func validateTemplates(ch *chart.Chart, vals map[string]interface{}) error {
+	options := chartutil.ReleaseOptions{
+		Name:      "release-name",
+		Namespace: "default",
+		Revision:  1,
+		IsInstall: true,
+	}
+	valuesToRender, err := chartutil.ToRenderValues(ch, vals, options, chartutil.DefaultCapabilities)
+	if err != nil {
+		return errors.Wrap(err, "template validation")
+	}
+	if _, err := engine.Render(ch, valuesToRender); err != nil {
+		return errors.Wrap(err, "template validation")
+	}
+	return nil
+}
+
 // validateVersion Verify that version is a Version, and error out if it is not.
 func validateVersion(ver string) error {
 	if _, err := semver.NewVersion(ver); err != nil {
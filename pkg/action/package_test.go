@@ -20,11 +20,15 @@ import (
 	"io/ioutil"
 	"os"
 	"path"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/Masterminds/semver/v3"
 
 	"helm.sh/helm/v3/internal/test/ensure"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
 )
 
 func TestPassphraseFileFetcher(t *testing.T) {
@@ -123,3 +127,46 @@ func TestValidateVersion(t *testing.T) {
 		})
 	}
 }
+
+func TestPackageValidateTemplates(t *testing.T) {
+	dir := ensure.TempDir(t)
+	defer os.RemoveAll(dir)
+
+	ch := &chart.Chart{
+		Metadata: &chart.Metadata{
+			APIVersion: chart.APIVersionV2,
+			Name:       "broken",
+			Version:    "0.1.0",
+		},
+		Templates: []*chart.File{
+			{Name: "templates/configmap.yaml", Data: []byte("name: {{ .Values.name ")},
+		},
+	}
+	if err := chartutil.SaveDir(ch, dir); err != nil {
+		t.Fatal(err)
+	}
+	chartPath := filepath.Join(dir, "broken")
+
+	client := NewPackage()
+	client.Destination = dir
+	if _, err := client.Run(chartPath, nil); err != nil {
+		t.Fatalf("Expected packaging without validation to succeed, got %s", err)
+	}
+
+	client.ValidateTemplates = true
+	_, err := client.Run(chartPath, nil)
+	if err == nil {
+		t.Fatal("Expected packaging with template validation to fail")
+	}
+	if !strings.Contains(err.Error(), "template validation") || !strings.Contains(err.Error(), "configmap.yaml") {
+		t.Errorf("Unexpected error: %s", err)
+	}
+
+	fixed := filepath.Join(chartPath, "templates", "configmap.yaml")
+	if err := ioutil.WriteFile(fixed, []byte("name: {{ .Values.name }}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Run(chartPath, nil); err != nil {
+		t.Errorf("Expected packaging a valid chart with template validation to succeed, got %s", err)
+	}
+}
@@ -18,6 +18,10 @@ package action
 
 import (
 	"io"
+	"io/ioutil"
+	"strings"
+
+	"github.com/pkg/errors"
 )
 
 // RegistryLogin performs a registry login operation.
@@ -32,7 +36,61 @@ func NewRegistryLogin(cfg *Configuration) *RegistryLogin {
 	}
 }
 
+// LoginOptions are the credentials and connection settings of a registry login.
+//
+// When Username, Password and TokenFile are all empty the login is anonymous,
+// which only succeeds with registries that permit it.
+type LoginOptions struct {
+	// Username is the registry username. Without one, Password is used as an
+	// identity token.
+	Username string
+	// Password is the registry password or identity token.
+	Password string
+	// TokenFile is the path of a file holding an identity token, used instead
+	// of Username and Password.
+	TokenFile string
+	// Insecure allows logging in to a registry over plain HTTP or with a
+	// certificate that is not trusted, for testing.
+	Insecure bool
+	// Verify checks the credentials with the registry without storing them.
+	Verify bool
+}
+
+// credentials returns the username and the password or identity token to log
+// in with.
+func (o LoginOptions) credentials() (string, string, error) {
+	if o.TokenFile == "" {
+		return o.Username, o.Password, nil
+	}
+	if o.Username != "" || o.Password != "" {
+		return "", "", errors.New("a token file cannot be used with a username or password")
+	}
+	data, err := ioutil.ReadFile(o.TokenFile)
+	if err != nil {
+		return "", "", errors.Wrap(err, "unable to read token file")
+	}
+	token := strings.TrimSpace(string(data))
+	if token == "" {
+		return "", "", errors.Errorf("token file %s is empty", o.TokenFile)
+	}
+	return "", token, nil
+}
+
 // Run executes the registry login operation
+//
+// Deprecated: use RunWithOptions.
 func (a *RegistryLogin) Run(out io.Writer, hostname string, username string, password string, insecure bool) error {
-	return a.cfg.RegistryClient.Login(hostname, username, password, insecure)
+	return a.RunWithOptions(out, hostname, LoginOptions{Username: username, Password: password, Insecure: insecure})
+}
+
+// RunWithOptions executes the registry login operation with the given options.
+func (a *RegistryLogin) RunWithOptions(out io.Writer, hostname string, opts LoginOptions) error {
+	username, secret, err := opts.credentials()
+	if err != nil {
+		return err
+	}
+	if opts.Verify {
+		return a.cfg.RegistryClient.VerifyLogin(hostname, username, secret, opts.Insecure)
+	}
+	return a.cfg.RegistryClient.Login(hostname, username, secret, opts.Insecure)
 }
@@ -0,0 +1,183 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+
+	"helm.sh/helm/v3/internal/experimental/registry"
+)
+
+func TestLoginOptionsCredentials(t *testing.T) {
+	tdir, err := ioutil.TempDir("", "helm-registry-login-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tdir)
+
+	tokenFile := filepath.Join(tdir, "token")
+	if err := ioutil.WriteFile(tokenFile, []byte("s3cr3t-token\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	emptyFile := filepath.Join(tdir, "empty")
+	if err := ioutil.WriteFile(emptyFile, nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		opts     LoginOptions
+		username string
+		secret   string
+		err      string
+	}{
+		{name: "password", opts: LoginOptions{Username: "user", Password: "pass"}, username: "user", secret: "pass"},
+		{name: "anonymous", opts: LoginOptions{}},
+		{name: "token file", opts: LoginOptions{TokenFile: tokenFile}, secret: "s3cr3t-token"},
+		{name: "token file with username", opts: LoginOptions{Username: "user", TokenFile: tokenFile}, err: "cannot be used with a username or password"},
+		{name: "empty token file", opts: LoginOptions{TokenFile: emptyFile}, err: "is empty"},
+		{name: "missing token file", opts: LoginOptions{TokenFile: filepath.Join(tdir, "missing")}, err: "unable to read token file"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			username, secret, err := tt.opts.credentials()
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Errorf("Expected an error containing %q, got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if username != tt.username || secret != tt.secret {
+				t.Errorf("Expected %q/%q, got %q/%q", tt.username, tt.secret, username, secret)
+			}
+		})
+	}
+}
+
+func TestRegistryLoginInsecure(t *testing.T) {
+	// A plain HTTP registry that allows anonymous access
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+	host := strings.TrimPrefix(srv.URL, "http://")
+
+	client, cleanup := newTestRegistryClient(t)
+	defer cleanup()
+
+	action := NewRegistryLogin(&Configuration{RegistryClient: client})
+	if err := action.RunWithOptions(ioutil.Discard, host, LoginOptions{Insecure: true}); err != nil {
+		t.Fatal(err)
+	}
+	hosts, err := client.Hosts()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hosts) != 1 || hosts[0] != host {
+		t.Errorf("Expected credentials for %s, got %v", host, hosts)
+	}
+}
+
+func TestRegistryLoginVerify(t *testing.T) {
+	// A plain HTTP registry that only accepts user/pass
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if u, p, ok := r.BasicAuth(); ok && u == "user" && p == "pass" {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		w.Header().Set("WWW-Authenticate", `Basic realm="test"`)
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer srv.Close()
+	host := strings.TrimPrefix(srv.URL, "http://")
+
+	// Nothing listens on this address once the server is closed
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closedHost := strings.TrimPrefix(closed.URL, "http://")
+	closed.Close()
+
+	tests := []struct {
+		name string
+		host string
+		opts LoginOptions
+		err  error
+	}{
+		{name: "valid", host: host, opts: LoginOptions{Username: "user", Password: "pass"}},
+		{name: "invalid", host: host, opts: LoginOptions{Username: "user", Password: "wrong"}, err: registry.ErrInvalidCredentials},
+		{name: "unreachable", host: closedHost, opts: LoginOptions{Username: "user", Password: "pass"}, err: registry.ErrRegistryUnreachable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, cleanup := newTestRegistryClient(t)
+			defer cleanup()
+
+			tt.opts.Insecure = true
+			tt.opts.Verify = true
+			err := NewRegistryLogin(&Configuration{RegistryClient: client}).RunWithOptions(ioutil.Discard, tt.host, tt.opts)
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Fatalf("Expected %q, got %v", tt.err, err)
+				}
+			} else if err != nil {
+				t.Fatal(err)
+			}
+
+			hosts, err := client.Hosts()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(hosts) != 0 {
+				t.Errorf("Expected no stored credentials, got %v", hosts)
+			}
+		})
+	}
+}
+
+// newTestRegistryClient returns a registry client whose credentials and cache
+// live in a temporary directory, removed by the returned function.
+func newTestRegistryClient(t *testing.T) (*registry.Client, func()) {
+	t.Helper()
+	tdir, err := ioutil.TempDir("", "helm-registry-login-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cache, err := registry.NewCache(registry.CacheOptRoot(filepath.Join(tdir, registry.CacheRootDir)))
+	if err != nil {
+		os.RemoveAll(tdir)
+		t.Fatal(err)
+	}
+	client, err := registry.NewClient(
+		registry.ClientOptCredentialsFile(filepath.Join(tdir, registry.CredentialsFileBasename)),
+		registry.ClientOptCache(cache),
+	)
+	if err != nil {
+		os.RemoveAll(tdir)
+		t.Fatal(err)
+	}
+	return client, func() { os.RemoveAll(tdir) }
+}
@@ -17,7 +17,11 @@ limitations under the License.
 package action
 
 import (
+	"fmt"
 	"io"
+	"strings"
+
+	"github.com/pkg/errors"
 )
 
 // RegistryLogout performs a registry login operation.
@@ -36,3 +40,38 @@ func NewRegistryLogout(cfg *Configuration) *RegistryLogout {
 func (a *RegistryLogout) Run(out io.Writer, hostname string) error {
 	return a.cfg.RegistryClient.Logout(hostname)
 }
+
+// RegistryLogoutAll logs out of every registry with stored credentials.
+type RegistryLogoutAll struct {
+	cfg *Configuration
+}
+
+// NewRegistryLogoutAll creates a new RegistryLogoutAll object with the given configuration.
+func NewRegistryLogoutAll(cfg *Configuration) *RegistryLogoutAll {
+	return &RegistryLogoutAll{
+		cfg: cfg,
+	}
+}
+
+// Run logs out of each registry in turn, reporting the result for each one.
+// A failure does not stop the others from being logged out, but is returned
+// once all of them have been tried.
+func (a *RegistryLogoutAll) Run(out io.Writer) error {
+	hosts, err := a.cfg.RegistryClient.Hosts()
+	if err != nil {
+		return err
+	}
+	var failed []string
+	for _, host := range hosts {
+		if err := a.cfg.RegistryClient.Logout(host); err != nil {
+			fmt.Fprintf(out, "Logout of %s failed: %s\n", host, err)
+			failed = append(failed, host)
+			continue
+		}
+		fmt.Fprintf(out, "Logged out of %s\n", host)
+	}
+	if len(failed) > 0 {
+		return errors.Errorf("unable to log out of %s", strings.Join(failed, ", "))
+	}
+	return nil
+}
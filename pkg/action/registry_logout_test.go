@@ -0,0 +1,78 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"helm.sh/helm/v3/internal/experimental/registry"
+)
+
+func TestRegistryLogoutAll(t *testing.T) {
+	tdir, err := ioutil.TempDir("", "helm-registry-logout-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tdir)
+
+	credentialsFile := filepath.Join(tdir, registry.CredentialsFileBasename)
+	credentials := `{"auths": {"one.example.com": {"auth": "dXNlcjpwYXNz"}, "two.example.com": {"auth": "dXNlcjpwYXNz"}}}`
+	if err := ioutil.WriteFile(credentialsFile, []byte(credentials), 0600); err != nil {
+		t.Fatal(err)
+	}
+	cache, err := registry.NewCache(registry.CacheOptRoot(filepath.Join(tdir, registry.CacheRootDir)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := registry.NewClient(
+		registry.ClientOptCredentialsFile(credentialsFile),
+		registry.ClientOptCache(cache),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hosts, err := client.Hosts()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hosts) != 2 || hosts[0] != "one.example.com" || hosts[1] != "two.example.com" {
+		t.Fatalf("Expected credentials for both hosts, got %v", hosts)
+	}
+
+	var out bytes.Buffer
+	action := NewRegistryLogoutAll(&Configuration{RegistryClient: client})
+	if err := action.Run(&out); err != nil {
+		t.Fatal(err)
+	}
+	expected := "Logged out of one.example.com\nLogged out of two.example.com\n"
+	if out.String() != expected {
+		t.Errorf("Expected %q, got %q", expected, out.String())
+	}
+
+	hosts, err = client.Hosts()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hosts) != 0 {
+		t.Errorf("Expected no credentials left, got %v", hosts)
+	}
+}
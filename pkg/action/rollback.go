@@ -26,6 +26,7 @@ import (
 
 	"helm.sh/helm/v3/pkg/chartutil"
 	"helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/releaseutil"
 	helmtime "helm.sh/helm/v3/pkg/time"
 )
 
@@ -56,8 +57,18 @@ func NewRollback(cfg *Configuration) *Rollback {
 
 // Run executes 'helm rollback' against the given release.
 func (r *Rollback) Run(name string) error {
+	namespace, err := r.run(name)
+	if !r.DryRun {
+		r.cfg.audit(AuditRollback, name, namespace, err)
+	}
+	return err
+}
+
+// run performs the rollback, returning the namespace of the release once it
+// is known.
+func (r *Rollback) run(name string) (string, error) {
 	if err := r.cfg.KubeClient.IsReachable(); err != nil {
-		return err
+		return "", err
 	}
 
 	r.cfg.Releases.MaxHistory = r.MaxHistory
@@ -65,28 +76,49 @@ func (r *Rollback) Run(name string) error {
 	r.cfg.Log("preparing rollback of %s", name)
 	currentRelease, targetRelease, err := r.prepareRollback(name)
 	if err != nil {
-		return err
+		return "", err
 	}
+	namespace := targetRelease.Namespace
 
 	if !r.DryRun {
 		r.cfg.Log("creating rolled back release for %s", name)
 		if err := r.cfg.Releases.Create(targetRelease); err != nil {
-			return err
+			return namespace, err
 		}
 	}
 
 	r.cfg.Log("performing rollback of %s", name)
 	if _, err := r.performRollback(currentRelease, targetRelease); err != nil {
-		return err
+		return namespace, err
 	}
 
 	if !r.DryRun {
 		r.cfg.Log("updating status for rolled back release for %s", name)
 		if err := r.cfg.Releases.Update(targetRelease); err != nil {
-			return err
+			return namespace, err
 		}
 	}
-	return nil
+	return namespace, nil
+}
+
+// History returns up to max revisions of the named release, most recent first,
+// which are the candidates for a rollback. All revisions are returned when max
+// is zero or negative.
+func (r *Rollback) History(name string, max int) ([]*release.Release, error) {
+	if err := chartutil.ValidateReleaseName(name); err != nil {
+		return nil, errors.Errorf("release name is invalid: %s", name)
+	}
+
+	hist, err := r.cfg.Releases.History(name)
+	if err != nil {
+		return nil, err
+	}
+
+	releaseutil.Reverse(hist, releaseutil.SortByRevision)
+	if max > 0 && len(hist) > max {
+		hist = hist[:max]
+	}
+	return hist, nil
 }
 
 // prepareRollback finds the previous release and prepares a new release object with
@@ -0,0 +1,101 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"helm.sh/helm/v3/pkg/release"
+)
+
+func TestRollbackHistory(t *testing.T) {
+	is := assert.New(t)
+	config := actionConfigFixture(t)
+
+	// Store the revisions out of order to make sure they are sorted.
+	for _, version := range []int{2, 4, 1, 3} {
+		status := release.StatusSuperseded
+		if version == 4 {
+			status = release.StatusDeployed
+		}
+		rel := namedReleaseStub("nemo", status)
+		rel.Version = version
+		rel.Info.Description = fmt.Sprintf("revision %d", version)
+		require.NoError(t, config.Releases.Create(rel))
+	}
+	require.NoError(t, config.Releases.Create(namedReleaseStub("dory", release.StatusDeployed)))
+
+	client := NewRollback(config)
+
+	hist, err := client.History("nemo", 0)
+	require.NoError(t, err)
+	var versions []int
+	for _, rel := range hist {
+		versions = append(versions, rel.Version)
+	}
+	is.Equal([]int{4, 3, 2, 1}, versions)
+	is.Equal(release.StatusDeployed, hist[0].Info.Status)
+	is.Equal("revision 4", hist[0].Info.Description)
+
+	hist, err = client.History("nemo", 2)
+	require.NoError(t, err)
+	is.Len(hist, 2)
+	is.Equal(4, hist[0].Version)
+	is.Equal(3, hist[1].Version)
+
+	_, err = client.History("not/valid", 1)
+	is.EqualError(err, "release name is invalid: not/valid")
+}
+
+func TestRollbackAudit(t *testing.T) {
+	is := assert.New(t)
+	config := actionConfigFixture(t)
+	recorder := &auditRecorder{}
+	config.Audit = recorder
+	config.AuditUser = "alice"
+
+	for _, version := range []int{1, 2} {
+		status := release.StatusSuperseded
+		if version == 2 {
+			status = release.StatusDeployed
+		}
+		rel := namedReleaseStub("nemo", status)
+		rel.Version = version
+		rel.Namespace = "reef"
+		require.NoError(t, config.Releases.Create(rel))
+	}
+
+	client := NewRollback(config)
+	require.NoError(t, client.Run("nemo"))
+	is.Len(recorder.events, 1)
+	e := recorder.events[0]
+	is.Equal(AuditRollback, e.Action)
+	is.Equal("nemo", e.Release)
+	is.Equal("reef", e.Namespace)
+	is.Equal("alice", e.User)
+	is.Equal(AuditSucceeded, e.Outcome)
+
+	client.Version = 7
+	is.Error(client.Run("nemo"))
+	is.Len(recorder.events, 2)
+	is.Equal(AuditFailed, recorder.events[1].Outcome)
+	is.NotEmpty(recorder.events[1].Error)
+}
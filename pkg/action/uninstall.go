@@ -50,6 +50,18 @@ func NewUninstall(cfg *Configuration) *Uninstall {
 
 // Run uninstalls the given release.
 func (u *Uninstall) Run(name string) (*release.UninstallReleaseResponse, error) {
+	res, err := u.run(name)
+	if !u.DryRun {
+		var namespace string
+		if res != nil && res.Release != nil {
+			namespace = res.Release.Namespace
+		}
+		u.cfg.audit(AuditUninstall, name, namespace, err)
+	}
+	return res, err
+}
+
+func (u *Uninstall) run(name string) (*release.UninstallReleaseResponse, error) {
 	if err := u.cfg.KubeClient.IsReachable(); err != nil {
 		return nil, err
 	}
@@ -17,6 +17,15 @@ package chart
 
 import "time"
 
+const (
+	// ConditionMatchAny enables a dependency when any value matching its
+	// condition is true.
+	ConditionMatchAny = "any"
+	// ConditionMatchAll enables a dependency when every value matching its
+	// condition is true.
+	ConditionMatchAll = "all"
+)
+
 // Dependency describes a chart upon which another chart depends.
 //
 // Dependencies can be used to express developer intent, or to capture the state
@@ -37,7 +46,13 @@ type Dependency struct {
 	// used to fetch the repository index.
 	Repository string `json:"repository"`
 	// A yaml path that resolves to a boolean, used for enabling/disabling charts (e.g. subchart1.enabled )
+	//
+	// Path elements may be glob patterns, such as features.*.enabled.
 	Condition string `json:"condition,omitempty"`
+	// ConditionMatch is "any" (the default) if a condition with glob patterns
+	// enables the chart when any matching value is true, or "all" if every
+	// matching value must be true.
+	ConditionMatch string `json:"condition-match,omitempty"`
 	// Tags can be used to group charts for enabling/disabling together
 	Tags []string `json:"tags,omitempty"`
 	// Enabled bool determines if chart should be loaded
@@ -57,12 +72,18 @@ func (d *Dependency) Validate() error {
 	d.Version = sanitizeString(d.Version)
 	d.Repository = sanitizeString(d.Repository)
 	d.Condition = sanitizeString(d.Condition)
+	d.ConditionMatch = sanitizeString(d.ConditionMatch)
 	for i := range d.Tags {
 		d.Tags[i] = sanitizeString(d.Tags[i])
 	}
 	if d.Alias != "" && !aliasNameFormat.MatchString(d.Alias) {
 		return ValidationErrorf("dependency %q has disallowed characters in the alias", d.Name)
 	}
+	switch d.ConditionMatch {
+	case "", ConditionMatchAny, ConditionMatchAll:
+	default:
+		return ValidationErrorf("dependency %q has an unknown condition-match %q, use %q or %q", d.Name, d.ConditionMatch, ConditionMatchAny, ConditionMatchAll)
+	}
 	return nil
 }
 
@@ -42,3 +42,20 @@ func TestValidateDependency(t *testing.T) {
 		}
 	}
 }
+
+func TestValidateDependencyConditionMatch(t *testing.T) {
+	for value, shouldFail := range map[string]bool{
+		"":                false,
+		ConditionMatchAny: false,
+		ConditionMatchAll: false,
+		"some":            true,
+	} {
+		dep := &Dependency{Name: "example", ConditionMatch: value}
+		res := dep.Validate()
+		if res != nil && !shouldFail {
+			t.Errorf("Failed on case %q", value)
+		} else if res == nil && shouldFail {
+			t.Errorf("Expected failure for %q", value)
+		}
+	}
+}
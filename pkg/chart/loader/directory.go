@@ -118,3 +118,38 @@ func LoadDir(dir string) (*chart.Chart, error) {
 
 	return LoadFiles(files)
 }
+
+// LoadCharts loads every chart found under root.
+//
+// A directory holding a Chart.yaml file is loaded as a chart, and its contents,
+// including any sub-charts, are not searched further. The charts are returned
+// keyed by their directory, along with an error for each chart that could not
+// be loaded.
+func LoadCharts(root string) (map[string]*chart.Chart, []error) {
+	charts := map[string]*chart.Chart{}
+	var errs []error
+
+	walk := func(name string, fi os.FileInfo, err error) error {
+		if err != nil {
+			errs = append(errs, err)
+			return nil
+		}
+		if !fi.IsDir() {
+			return nil
+		}
+		if _, err := os.Stat(filepath.Join(name, "Chart.yaml")); err != nil {
+			return nil
+		}
+		c, err := LoadDir(name)
+		if err != nil {
+			errs = append(errs, errors.Wrapf(err, "unable to load chart in %s", name))
+		} else {
+			charts[name] = c
+		}
+		return filepath.SkipDir
+	}
+	if err := filepath.Walk(root, walk); err != nil {
+		errs = append(errs, err)
+	}
+	return charts, errs
+}
@@ -651,3 +651,56 @@ func verifyBomStripped(t *testing.T, files []*chart.File) {
 		}
 	}
 }
+
+func TestLoadCharts(t *testing.T) {
+	root, err := ioutil.TempDir("", "helm-test-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	files := map[string]string{
+		"apps/web/Chart.yaml":            "apiVersion: v2\nname: web\nversion: 0.1.0\n",
+		"apps/web/charts/db/Chart.yaml":  "apiVersion: v2\nname: db\nversion: 0.1.0\n",
+		"apps/worker/Chart.yaml":         "apiVersion: v2\nname: worker\nversion: 1.0.0\n",
+		"broken/Chart.yaml":              "apiVersion: v2\nversion: 0.1.0\n",
+		"docs/README.md":                 "not a chart\n",
+		"docs/examples/values.yaml":      "replicas: 1\n",
+		"apps/worker/templates/job.yaml": "kind: Job\n",
+	}
+	for name, data := range files {
+		name = filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(name, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	charts, errs := LoadCharts(root)
+
+	if len(charts) != 2 {
+		t.Fatalf("Expected 2 charts, got %d: %v", len(charts), charts)
+	}
+	for dir, name := range map[string]string{"apps/web": "web", "apps/worker": "worker"} {
+		c, ok := charts[filepath.Join(root, filepath.FromSlash(dir))]
+		if !ok {
+			t.Errorf("Expected a chart for %s", dir)
+			continue
+		}
+		if c.Name() != name {
+			t.Errorf("Expected chart %s in %s, got %s", name, dir, c.Name())
+		}
+	}
+	if deps := charts[filepath.Join(root, "apps", "web")].Dependencies(); len(deps) != 1 || deps[0].Name() != "db" {
+		t.Errorf("Expected the web chart to hold its db sub-chart, got %v", deps)
+	}
+
+	if len(errs) != 1 {
+		t.Fatalf("Expected 1 error, got %d: %v", len(errs), errs)
+	}
+	if !strings.Contains(errs[0].Error(), filepath.Join(root, "broken")) {
+		t.Errorf("Expected the error to name the broken chart, got %q", errs[0])
+	}
+}
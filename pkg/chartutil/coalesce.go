@@ -48,6 +48,70 @@ func CoalesceValues(chrt *chart.Chart, vals map[string]interface{}) (Values, err
 	return coalesce(chrt, valsCopy)
 }
 
+// CoalesceValuesDeleteNulls coalesces values like CoalesceValues, but treats
+// every explicit null as a request to delete the key.
+//
+// CoalesceValues removes a key set to null only when a chart default exists
+// for it; a null for a key without a default is left in the result. In this
+// mode those leftover nulls are pruned as well, so an explicit null in an
+// override never appears in the coalesced values. This is the recommended way
+// to disable a chart default entirely. Nulls in the chart defaults are not
+// requests to delete and are kept as CoalesceValues leaves them.
+func CoalesceValuesDeleteNulls(chrt *chart.Chart, vals map[string]interface{}) (Values, error) {
+	v, err := CoalesceValues(chrt, vals)
+	if err != nil {
+		return v, err
+	}
+	// The coalesced values share tables with the chart defaults, which must
+	// not be pruned in place.
+	c, err := copystructure.Copy(map[string]interface{}(v))
+	if err != nil {
+		return v, err
+	}
+	pruned := c.(map[string]interface{})
+	deleteNulls(pruned, vals)
+	return pruned, nil
+}
+
+// CoalesceValuesCoerceTypes coalesces values like CoalesceValues, then
+// converts string values to the integer, number or boolean type the schema of
+// the chart, or of the subchart they belong to, declares for them.
+//
+// This lets a value given as a string, such as with --set-string, be used
+// where the chart expects a number or a boolean. Strings that cannot be
+// converted, and values the schemas do not describe, are left as they are.
+func CoalesceValuesCoerceTypes(chrt *chart.Chart, vals map[string]interface{}) (Values, error) {
+	v, err := CoalesceValues(chrt, vals)
+	if err != nil {
+		return v, err
+	}
+	// The coalesced values share tables with the chart defaults, which must
+	// not be converted in place.
+	c, err := copystructure.Copy(map[string]interface{}(v))
+	if err != nil {
+		return v, err
+	}
+	coerced := c.(map[string]interface{})
+	if err := coerceToSchemas(chrt, coerced); err != nil {
+		return v, err
+	}
+	return coerced, nil
+}
+
+// deleteNulls recursively removes from dst the keys that are set to null in
+// vals.
+func deleteNulls(dst, vals map[string]interface{}) {
+	for key, val := range vals {
+		if val == nil {
+			delete(dst, key)
+		} else if src, ok := val.(map[string]interface{}); ok {
+			if sub, ok := dst[key].(map[string]interface{}); ok {
+				deleteNulls(sub, src)
+			}
+		}
+	}
+}
+
 // coalesce coalesces the dest values and the chart values, giving priority to the dest values.
 //
 // This is a helper function for CoalesceValues.
@@ -184,6 +184,143 @@ func TestCoalesceValues(t *testing.T) {
 	is.Equal(valsCopy, vals)
 }
 
+func TestCoalesceValuesCoerceTypes(t *testing.T) {
+	is := assert.New(t)
+
+	c := withDeps(&chart.Chart{
+		Metadata: &chart.Metadata{Name: "moby"},
+		Values: map[string]interface{}{
+			"image": map[string]interface{}{"tag": "latest", "pullSecrets": "true"},
+		},
+		Schema: []byte(`{
+  "type": "object",
+  "properties": {
+    "replicas": {"type": "integer"},
+    "ratio": {"type": "number"},
+    "debug": {"type": "boolean"},
+    "port": {"type": ["integer", "null"]},
+    "name": {"type": ["string", "integer"]},
+    "image": {"type": "object", "properties": {"pullSecrets": {"type": "boolean"}}},
+    "ports": {"type": "array", "items": {"type": "integer"}}
+  }
+}`),
+	},
+		&chart.Chart{
+			Metadata: &chart.Metadata{Name: "pequod"},
+			Schema:   []byte(`{"properties": {"enabled": {"type": "boolean"}}}`),
+		},
+	)
+
+	vals := map[string]interface{}{
+		"replicas":   "3",
+		"ratio":      "0.5",
+		"debug":      "true",
+		"port":       "8080",
+		"name":       "42",
+		"unschemed":  "3",
+		"ports":      []interface{}{"80", "443"},
+		"pequod":     map[string]interface{}{"enabled": "false"},
+		"notANumber": "many",
+	}
+	v, err := CoalesceValuesCoerceTypes(c, vals)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	is.Equal(int64(3), v["replicas"])
+	is.Equal(0.5, v["ratio"])
+	is.Equal(true, v["debug"])
+	is.Equal(int64(8080), v["port"])
+	is.Equal("42", v["name"], "a type list allowing a string keeps the string")
+	is.Equal("3", v["unschemed"], "keys without a schema are left untouched")
+	is.Equal("many", v["notANumber"])
+	is.Equal([]interface{}{int64(80), int64(443)}, v["ports"])
+	is.Equal(false, v["pequod"].(map[string]interface{})["enabled"])
+	// A chart default is converted in the result only
+	is.Equal(true, v["image"].(map[string]interface{})["pullSecrets"])
+	is.Equal("true", c.Values["image"].(map[string]interface{})["pullSecrets"])
+
+	// CoalesceValues does not convert anything
+	legacy, err := CoalesceValues(c, vals)
+	if err != nil {
+		t.Fatal(err)
+	}
+	is.Equal("3", legacy["replicas"])
+}
+
+func TestCoalesceValuesDeleteNulls(t *testing.T) {
+	c := withDeps(&chart.Chart{
+		Metadata: &chart.Metadata{Name: "moby"},
+		Values: map[string]interface{}{
+			"image":   map[string]interface{}{"repository": "moby", "tag": "latest"},
+			"service": map[string]interface{}{"nodePort": nil},
+		},
+	},
+		&chart.Chart{
+			Metadata: &chart.Metadata{Name: "pequod"},
+			Values: map[string]interface{}{
+				"resources": map[string]interface{}{"limits": map[string]interface{}{"cpu": "100m"}},
+			},
+		},
+	)
+
+	vals := map[string]interface{}{
+		"image":   map[string]interface{}{"tag": nil, "pullPolicy": nil},
+		"pequod":  map[string]interface{}{"resources": nil},
+		"unknown": nil,
+	}
+
+	legacy, err := CoalesceValues(c, vals)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The legacy mode keeps nulls for keys that have no chart default
+	if v, ok := legacy["unknown"]; !ok || v != nil {
+		t.Errorf("Expected legacy mode to keep the null unknown key, got %v (present: %t)", v, ok)
+	}
+	if v, ok := legacy["image"].(map[string]interface{})["pullPolicy"]; !ok || v != nil {
+		t.Errorf("Expected legacy mode to keep the null image.pullPolicy key, got %v (present: %t)", v, ok)
+	}
+
+	v, err := CoalesceValuesDeleteNulls(c, vals)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	image := v["image"].(map[string]interface{})
+	if _, ok := image["tag"]; ok {
+		t.Error("Expected image.tag default to be removed, still present")
+	}
+	if _, ok := image["pullPolicy"]; ok {
+		t.Error("Expected image.pullPolicy to be removed, still present")
+	}
+	if image["repository"] != "moby" {
+		t.Errorf("Expected image.repository to be kept, got %v", image["repository"])
+	}
+	if _, ok := v["pequod"].(map[string]interface{})["resources"]; ok {
+		t.Error("Expected subchart resources default to be removed, still present")
+	}
+	if _, ok := v["unknown"]; ok {
+		t.Error("Expected unknown key to be removed, still present")
+	}
+	// A null in the chart defaults is not an override and is kept
+	if np, ok := v["service"].(map[string]interface{})["nodePort"]; !ok || np != nil {
+		t.Errorf("Expected the null nodePort default to be kept, got %v (present: %t)", np, ok)
+	}
+
+	// The chart defaults must not be modified
+	if tag := c.Values["image"].(map[string]interface{})["tag"]; tag != "latest" {
+		t.Errorf("Expected chart default image.tag to be kept, got %v", tag)
+	}
+	if _, ok := c.Values["service"].(map[string]interface{})["nodePort"]; !ok {
+		t.Error("Expected chart default service.nodePort to be kept, was removed")
+	}
+	sub := c.Dependencies()[0].Values
+	if _, ok := sub["resources"]; !ok {
+		t.Error("Expected subchart default resources to be kept, was removed")
+	}
+}
+
 func TestCoalesceTables(t *testing.T) {
 	dst := map[string]interface{}{
 		"name": "Ishmael",
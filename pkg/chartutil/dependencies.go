@@ -16,34 +16,142 @@ limitations under the License.
 package chartutil
 
 import (
+	"fmt"
 	"log"
+	"path"
+	"sort"
 	"strings"
 
+	"github.com/Masterminds/semver/v3"
+	"github.com/mitchellh/copystructure"
+	"github.com/pkg/errors"
+
 	"helm.sh/helm/v3/pkg/chart"
 )
 
+// DependencyState is the outcome of processing a single dependency.
+type DependencyState string
+
+const (
+	// DependencyEnabled means the dependency is enabled and its chart was found.
+	DependencyEnabled DependencyState = "enabled"
+	// DependencyDisabled means the dependency was disabled by a condition or tag.
+	DependencyDisabled DependencyState = "disabled"
+	// DependencyMissing means the dependency is enabled but no chart matching
+	// its name and version constraint was found.
+	DependencyMissing DependencyState = "missing"
+)
+
+// DependencyReason is what decided whether a dependency is enabled.
+type DependencyReason string
+
+const (
+	// DependencyReasonDefault means no tag or condition applied to the
+	// dependency, so it is enabled.
+	DependencyReasonDefault DependencyReason = "default"
+	// DependencyReasonTag means the dependency's tags decided whether it is
+	// enabled.
+	DependencyReasonTag DependencyReason = "tag"
+	// DependencyReasonCondition means the dependency's condition decided
+	// whether it is enabled. Conditions take precedence over tags.
+	DependencyReasonCondition DependencyReason = "condition"
+)
+
+// DependencyEvent describes a dependency as it is processed.
+type DependencyEvent struct {
+	// Path is the dotted path of the dependency from the top level chart,
+	// which is also where its values live, such as "subchart1.subchartA".
+	Path string
+	// Name is the name of the dependency, or its alias if one is set.
+	Name string
+	// Version is the version constraint of the dependency.
+	Version string
+	// State is the outcome of processing the dependency.
+	State DependencyState
+	// Reason is what decided whether the dependency is enabled.
+	Reason DependencyReason
+}
+
+// DependencyOptions configures how ProcessDependenciesWithOptions processes
+// the dependencies of a chart.
+type DependencyOptions struct {
+	// Strict fails if an import-values entry references a table that the
+	// sub-chart does not have, instead of logging a warning.
+	Strict bool
+	// Progress, if set, is called once for every dependency of the chart and
+	// of its enabled sub-charts as soon as it is known whether it is enabled.
+	// Dependencies of disabled sub-charts are not reported.
+	Progress func(DependencyEvent)
+}
+
 // ProcessDependencies checks through this chart's dependencies, processing accordingly.
 func ProcessDependencies(c *chart.Chart, v Values) error {
-	if err := processDependencyEnabled(c, v, ""); err != nil {
-		return err
+	_, err := ProcessDependenciesWithOptions(c, v, DependencyOptions{})
+	return err
+}
+
+// ProcessDependenciesWithOptions processes the chart's dependencies like
+// ProcessDependencies, as configured by opts. It returns a warning for every
+// enabled dependency that has no chart because only charts with other
+// versions were found, such as
+// "dependency db: requested >=2.0 but found 1.4".
+func ProcessDependenciesWithOptions(c *chart.Chart, v Values, opts DependencyOptions) ([]string, error) {
+	if err := checkDependencyCycles(c, nil); err != nil {
+		return nil, err
+	}
+	var warnings []string
+	if err := processDependencyEnabled(c, v, "", opts.Progress, &warnings); err != nil {
+		return warnings, err
 	}
-	return processDependencyImportValues(c)
+	return warnings, processDependencyImportValues(c, opts.Strict)
 }
 
-// processDependencyConditions disables charts based on condition path value in values
-func processDependencyConditions(reqs []*chart.Dependency, cvals Values, cpath string) {
-	if reqs == nil {
-		return
+// checkDependencyCycles returns an error if a chart depends on itself through
+// its sub-charts. chain holds the charts leading to c. Processing such a chart
+// would never finish.
+func checkDependencyCycles(c *chart.Chart, chain []*chart.Chart) error {
+	for i, parent := range chain {
+		if parent != c {
+			continue
+		}
+		var names []string
+		for _, p := range chain[i:] {
+			names = append(names, p.Name())
+		}
+		names = append(names, c.Name())
+		return errors.Errorf("circular dependency detected: %s", strings.Join(names, " -> "))
+	}
+	chain = append(chain, c)
+	for _, dep := range c.Dependencies() {
+		if err := checkDependencyCycles(dep, chain); err != nil {
+			return err
+		}
 	}
+	return nil
+}
+
+// processDependencyConditions disables charts based on condition path value in
+// values. It returns the dependencies whose condition applied.
+func processDependencyConditions(reqs []*chart.Dependency, cvals Values, cpath string) map[*chart.Dependency]bool {
+	decided := map[*chart.Dependency]bool{}
 	for _, r := range reqs {
 		for _, c := range strings.Split(strings.TrimSpace(r.Condition), ",") {
 			if len(c) > 0 {
+				if strings.ContainsAny(c, "*?[") {
+					if enabled, ok := globConditionEnabled(r, cvals, cpath, c); ok {
+						r.Enabled = enabled
+						decided[r] = true
+						break
+					}
+					continue
+				}
 				// retrieve value
 				vv, err := cvals.PathValue(cpath + c)
 				if err == nil {
 					// if not bool, warn
 					if bv, ok := vv.(bool); ok {
 						r.Enabled = bv
+						decided[r] = true
 						break
 					} else {
 						log.Printf("Warning: Condition path '%s' for chart %s returned non-bool value", c, r.Name)
@@ -55,16 +163,83 @@ func processDependencyConditions(reqs []*chart.Dependency, cvals Values, cpath s
 			}
 		}
 	}
+	return decided
 }
 
-// processDependencyTags disables charts based on tags in values
-func processDependencyTags(reqs []*chart.Dependency, cvals Values) {
-	if reqs == nil {
-		return
+// globConditionEnabled evaluates a condition path containing glob patterns.
+// The bool values matching the path are combined according to the
+// dependency's ConditionMatch. ok is false when no bool value matches.
+func globConditionEnabled(r *chart.Dependency, cvals Values, cpath, condition string) (enabled, ok bool) {
+	scope := cvals
+	if cpath != "" {
+		t, err := cvals.Table(strings.TrimSuffix(cpath, "."))
+		if err != nil {
+			return false, false
+		}
+		scope = t
 	}
+
+	var matches []interface{}
+	if err := globValues(scope, strings.Split(condition, "."), &matches); err != nil {
+		log.Printf("Warning: Condition path '%s' for chart %s is invalid: %s", condition, r.Name, err)
+		return false, false
+	}
+
+	all := r.ConditionMatch == chart.ConditionMatchAll
+	enabled = all
+	for _, m := range matches {
+		bv, isBool := m.(bool)
+		if !isBool {
+			log.Printf("Warning: Condition path '%s' for chart %s matched a non-bool value", condition, r.Name)
+			continue
+		}
+		ok = true
+		if all {
+			enabled = enabled && bv
+		} else {
+			enabled = enabled || bv
+		}
+	}
+	return enabled, ok
+}
+
+// globValues appends the values at the paths matching the glob patterns in
+// pattern to matches, in key order.
+func globValues(v map[string]interface{}, pattern []string, matches *[]interface{}) error {
+	keys := make([]string, 0, len(v))
+	for k := range v {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		matched, err := path.Match(pattern[0], k)
+		if err != nil {
+			return err
+		}
+		if !matched {
+			continue
+		}
+		if len(pattern) == 1 {
+			*matches = append(*matches, v[k])
+			continue
+		}
+		if t, ok := v[k].(map[string]interface{}); ok {
+			if err := globValues(t, pattern[1:], matches); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// processDependencyTags disables charts based on tags in values. It returns the
+// dependencies with a tag set in values.
+func processDependencyTags(reqs []*chart.Dependency, cvals Values) map[*chart.Dependency]bool {
+	decided := map[*chart.Dependency]bool{}
 	vt, err := cvals.Table("tags")
 	if err != nil {
-		return
+		return decided
 	}
 	for _, r := range reqs {
 		var hasTrue, hasFalse bool
@@ -87,7 +262,11 @@ func processDependencyTags(reqs []*chart.Dependency, cvals Values) {
 		} else if hasTrue || !hasTrue && !hasFalse {
 			r.Enabled = true
 		}
+		if hasTrue || hasFalse {
+			decided[r] = true
+		}
 	}
+	return decided
 }
 
 func getAliasDependency(charts []*chart.Chart, dep *chart.Dependency) *chart.Chart {
@@ -114,8 +293,23 @@ func getAliasDependency(charts []*chart.Chart, dep *chart.Dependency) *chart.Cha
 	return nil
 }
 
+// versionMismatch describes the charts named like dep whose versions do not
+// satisfy its version constraint. It returns an empty string if there are none.
+func versionMismatch(charts []*chart.Chart, dep *chart.Dependency) string {
+	var found []string
+	for _, c := range charts {
+		if c != nil && c.Name() == dep.Name {
+			found = append(found, c.Metadata.Version)
+		}
+	}
+	if len(found) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("requested %s but found %s", dep.Version, strings.Join(found, ", "))
+}
+
 // processDependencyEnabled removes disabled charts from dependencies
-func processDependencyEnabled(c *chart.Chart, v map[string]interface{}, path string) error {
+func processDependencyEnabled(c *chart.Chart, v map[string]interface{}, path string, progress func(DependencyEvent), warnings *[]string) error {
 	if c.Metadata.Dependencies == nil {
 		return nil
 	}
@@ -136,13 +330,25 @@ Loop:
 		chartDependencies = append(chartDependencies, existing)
 	}
 
+	resolved := map[string]bool{}
+	mismatched := map[string]string{}
 	for _, req := range c.Metadata.Dependencies {
-		if chartDependency := getAliasDependency(c.Dependencies(), req); chartDependency != nil {
+		var mismatch string
+		chartDependency := getAliasDependency(c.Dependencies(), req)
+		if chartDependency != nil {
 			chartDependencies = append(chartDependencies, chartDependency)
+		} else {
+			mismatch = versionMismatch(c.Dependencies(), req)
 		}
 		if req.Alias != "" {
 			req.Name = req.Alias
 		}
+		if chartDependency != nil {
+			resolved[req.Name] = true
+		}
+		if mismatch != "" {
+			mismatched[req.Name] = mismatch
+		}
 	}
 	c.SetDependencies(chartDependencies...)
 
@@ -155,27 +361,42 @@ Loop:
 		return err
 	}
 	// flag dependencies as enabled/disabled
-	processDependencyTags(c.Metadata.Dependencies, cvals)
-	processDependencyConditions(c.Metadata.Dependencies, cvals, path)
+	byTag := processDependencyTags(c.Metadata.Dependencies, cvals)
+	byCondition := processDependencyConditions(c.Metadata.Dependencies, cvals, path)
 	// make a map of charts to remove
 	rm := map[string]struct{}{}
 	for _, r := range c.Metadata.Dependencies {
+		state := DependencyEnabled
 		if !r.Enabled {
 			// remove disabled chart
 			rm[r.Name] = struct{}{}
+			state = DependencyDisabled
+		} else if !resolved[r.Name] {
+			state = DependencyMissing
+		}
+		if state == DependencyMissing && mismatched[r.Name] != "" && warnings != nil {
+			*warnings = append(*warnings, fmt.Sprintf("dependency %s: %s", path+r.Name, mismatched[r.Name]))
+		}
+		if progress != nil {
+			reason := DependencyReasonDefault
+			if byCondition[r] {
+				reason = DependencyReasonCondition
+			} else if byTag[r] {
+				reason = DependencyReasonTag
+			}
+			progress(DependencyEvent{Path: path + r.Name, Name: r.Name, Version: r.Version, State: state, Reason: reason})
 		}
 	}
-	// don't keep disabled charts in new slice
-	cd := []*chart.Chart{}
-	copy(cd, c.Dependencies()[:0])
+	// don't keep disabled charts in new slice. Aliased charts were renamed
+	// above, so they are matched by their alias.
+	cd := make([]*chart.Chart, 0, len(c.Dependencies()))
 	for _, n := range c.Dependencies() {
 		if _, ok := rm[n.Metadata.Name]; !ok {
 			cd = append(cd, n)
 		}
 	}
 	// don't keep disabled charts in metadata
-	cdMetadata := []*chart.Dependency{}
-	copy(cdMetadata, c.Metadata.Dependencies[:0])
+	cdMetadata := make([]*chart.Dependency, 0, len(c.Metadata.Dependencies))
 	for _, n := range c.Metadata.Dependencies {
 		if _, ok := rm[n.Name]; !ok {
 			cdMetadata = append(cdMetadata, n)
@@ -185,7 +406,7 @@ Loop:
 	// recursively call self to process sub dependencies
 	for _, t := range cd {
 		subpath := path + t.Metadata.Name + "."
-		if err := processDependencyEnabled(t, cvals, subpath); err != nil {
+		if err := processDependencyEnabled(t, cvals, subpath, progress, warnings); err != nil {
 			return err
 		}
 	}
@@ -217,7 +438,13 @@ func set(path []string, data map[string]interface{}) map[string]interface{} {
 }
 
 // processImportValues merges values from child to parent based on the chart's dependencies' ImportValues field.
-func processImportValues(c *chart.Chart) error {
+//
+// Imports are applied in the order they are declared in Chart.yaml, first by
+// dependency and then by import-values entry, so a later import of a key
+// overrides an earlier one. Values set in the parent chart take precedence
+// over imports using the child/parent form, while values imported through
+// exports take precedence over the parent chart's values.
+func processImportValues(c *chart.Chart, strict bool) error {
 	if c.Metadata.Dependencies == nil {
 		return nil
 	}
@@ -226,7 +453,8 @@ func processImportValues(c *chart.Chart) error {
 	if err != nil {
 		return err
 	}
-	b := make(map[string]interface{})
+	imported := make(map[string]interface{})
+	exported := make(map[string]interface{})
 	// import values from each dependency if specified in import-values
 	for _, r := range c.Metadata.Dependencies {
 		var outiv []interface{}
@@ -244,23 +472,37 @@ func processImportValues(c *chart.Chart) error {
 				// get child table
 				vv, err := cvals.Table(r.Name + "." + child)
 				if err != nil {
+					if strict {
+						return errors.Wrapf(err, "import-values of chart %s", r.Name)
+					}
 					log.Printf("Warning: ImportValues missing table from chart %s: %v", r.Name, err)
 					continue
 				}
+				vm, err := copyTable(vv)
+				if err != nil {
+					return err
+				}
 				// create value map from child to be merged into parent
-				b = CoalesceTables(cvals, pathToMap(parent, vv.AsMap()))
+				imported = CoalesceTables(pathToMap(parent, vm), imported)
 			case string:
 				child := "exports." + iv
 				outiv = append(outiv, map[string]string{
 					"child":  child,
 					"parent": ".",
 				})
-				vm, err := cvals.Table(r.Name + "." + child)
+				vv, err := cvals.Table(r.Name + "." + child)
 				if err != nil {
+					if strict {
+						return errors.Wrapf(err, "import-values of chart %s", r.Name)
+					}
 					log.Printf("Warning: ImportValues missing table: %v", err)
 					continue
 				}
-				b = CoalesceTables(b, vm.AsMap())
+				vm, err := copyTable(vv)
+				if err != nil {
+					return err
+				}
+				exported = CoalesceTables(vm, exported)
 			}
 		}
 		// set our formatted import values
@@ -268,18 +510,126 @@ func processImportValues(c *chart.Chart) error {
 	}
 
 	// set the new values
-	c.Values = CoalesceTables(b, cvals)
+	c.Values = CoalesceTables(exported, CoalesceTables(cvals, imported))
 
 	return nil
 }
 
+// copyTable returns a deep copy of a values table, so that imports can be
+// merged without modifying the child chart's values.
+func copyTable(v Values) (map[string]interface{}, error) {
+	vc, err := copystructure.Copy(v.AsMap())
+	if err != nil {
+		return nil, err
+	}
+	return vc.(map[string]interface{}), nil
+}
+
 // processDependencyImportValues imports specified chart values from child to parent.
-func processDependencyImportValues(c *chart.Chart) error {
+func processDependencyImportValues(c *chart.Chart, strict bool) error {
 	for _, d := range c.Dependencies() {
 		// recurse
-		if err := processDependencyImportValues(d); err != nil {
+		if err := processDependencyImportValues(d, strict); err != nil {
 			return err
 		}
 	}
-	return processImportValues(c)
+	return processImportValues(c, strict)
+}
+
+// ExplainDependency describes why the sub-chart with the given name or alias is
+// part of the chart c. There is one line for every parent chart that requires it,
+// giving the chain of charts leading to it together with the alias, version
+// constraint, condition and tags of the requirement. Lines are sorted so the
+// output does not depend on the order the sub-charts were loaded in.
+func ExplainDependency(c *chart.Chart, name string) (string, error) {
+	var lines []string
+	explainDependency(c, name, c.Name(), &lines)
+	if len(lines) == 0 {
+		return "", errors.Errorf("chart %q has no dependency named %q", c.Name(), name)
+	}
+	sort.Strings(lines)
+	return strings.Join(lines, "\n"), nil
+}
+
+func explainDependency(c *chart.Chart, name, path string, lines *[]string) {
+	for _, sub := range c.Dependencies() {
+		subPath := path + " > " + sub.Name()
+		for _, req := range c.Metadata.Dependencies {
+			if req.Name != sub.Name() || (sub.Name() != name && req.Alias != name) {
+				continue
+			}
+			*lines = append(*lines, describeRequirement(c.Name(), subPath, req))
+		}
+		explainDependency(sub, name, subPath, lines)
+	}
+}
+
+func describeRequirement(parent, path string, req *chart.Dependency) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s is required by %s (%s)", req.Name, parent, path)
+	if req.Alias != "" {
+		fmt.Fprintf(&b, " as alias %q", req.Alias)
+	}
+	if req.Version != "" {
+		fmt.Fprintf(&b, ", version constraint %q", req.Version)
+	}
+	if req.Condition != "" {
+		fmt.Fprintf(&b, ", condition %q", req.Condition)
+	}
+	if len(req.Tags) > 0 {
+		fmt.Fprintf(&b, ", tags %q", req.Tags)
+	}
+	return b.String()
+}
+
+// DependencyStatus describes a dependency declared in Chart.yaml and the chart
+// found for it among the sub-charts of the chart.
+type DependencyStatus struct {
+	// Name is the name of the dependency.
+	Name string
+	// Alias is the alias of the dependency, if any.
+	Alias string
+	// Constraint is the version constraint of the dependency.
+	Constraint string
+	// Repository is the repository the dependency is fetched from.
+	Repository string
+	// Present reports whether a sub-chart with the dependency's name exists.
+	Present bool
+	// Version is the version of the sub-chart, empty if it is not present.
+	Version string
+	// Satisfied reports whether the version of the sub-chart satisfies the
+	// constraint.
+	Satisfied bool
+}
+
+// ListDependencies reports, for each dependency declared in the Chart.yaml of c,
+// whether a sub-chart with that name is present and whether its version
+// satisfies the dependency's constraint. When several sub-charts share the name,
+// one satisfying the constraint is preferred. An error is returned if a
+// constraint cannot be parsed.
+func ListDependencies(c *chart.Chart) ([]DependencyStatus, error) {
+	var statuses []DependencyStatus
+	for _, dep := range c.Metadata.Dependencies {
+		if _, err := semver.NewConstraint(dep.Version); err != nil {
+			return nil, errors.Wrapf(err, "dependency %q has an invalid version constraint %q", dep.Name, dep.Version)
+		}
+		status := DependencyStatus{
+			Name:       dep.Name,
+			Alias:      dep.Alias,
+			Constraint: dep.Version,
+			Repository: dep.Repository,
+		}
+		for _, sub := range c.Dependencies() {
+			if sub.Name() != dep.Name {
+				continue
+			}
+			status.Present = true
+			status.Version = sub.Metadata.Version
+			if status.Satisfied = IsCompatibleRange(dep.Version, sub.Metadata.Version); status.Satisfied {
+				break
+			}
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
 }
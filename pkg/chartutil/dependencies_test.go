@@ -17,8 +17,10 @@ package chartutil
 import (
 	"os"
 	"path/filepath"
+	"reflect"
 	"sort"
 	"strconv"
+	"strings"
 	"testing"
 
 	"helm.sh/helm/v3/pkg/chart"
@@ -116,7 +118,7 @@ func TestDependencyEnabled(t *testing.T) {
 	for _, tc := range tests {
 		c := loadChart(t, "testdata/subpop")
 		t.Run(tc.name, func(t *testing.T) {
-			if err := processDependencyEnabled(c, tc.v, ""); err != nil {
+			if err := processDependencyEnabled(c, tc.v, "", nil, nil); err != nil {
 				t.Fatalf("error processing enabled dependencies %v", err)
 			}
 
@@ -133,6 +135,185 @@ func TestDependencyEnabled(t *testing.T) {
 	}
 }
 
+func TestDependencyEnabledGlobalCondition(t *testing.T) {
+	type M = map[string]interface{}
+	newChart := func() *chart.Chart {
+		leaf := &chart.Chart{
+			Metadata: &chart.Metadata{Name: "leaf", Version: "0.1.0"},
+		}
+		middle := &chart.Chart{
+			Metadata: &chart.Metadata{
+				Name:    "middle",
+				Version: "0.1.0",
+				Dependencies: []*chart.Dependency{
+					{Name: "leaf", Version: "0.1.0", Condition: "global.featureX.enabled"},
+				},
+			},
+			Values: M{"global": M{"featureX": M{"enabled": true}}},
+		}
+		middle.AddDependency(leaf)
+		top := &chart.Chart{
+			Metadata: &chart.Metadata{
+				Name:         "top",
+				Version:      "0.1.0",
+				Dependencies: []*chart.Dependency{{Name: "middle", Version: "0.1.0"}},
+			},
+			Values: M{},
+		}
+		top.AddDependency(middle)
+		return top
+	}
+
+	tests := []struct {
+		name string
+		v    M
+		e    []string
+	}{{
+		"sub-chart default enables the nested chart",
+		M{},
+		[]string{"top", "top.middle", "top.middle.leaf"},
+	}, {
+		"top-level global disables the nested chart",
+		M{"global": M{"featureX": M{"enabled": false}}},
+		[]string{"top", "top.middle"},
+	}, {
+		"top-level global enables the nested chart",
+		M{"global": M{"featureX": M{"enabled": true}}},
+		[]string{"top", "top.middle", "top.middle.leaf"},
+	}}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			c := newChart()
+			if err := processDependencyEnabled(c, tc.v, "", nil, nil); err != nil {
+				t.Fatalf("error processing enabled dependencies %v", err)
+			}
+			names := extractChartNames(c)
+			if strings.Join(names, ",") != strings.Join(tc.e, ",") {
+				t.Errorf("got %v, expected %v", names, tc.e)
+			}
+		})
+	}
+}
+
+func TestDependencyEnabledGlobCondition(t *testing.T) {
+	type M = map[string]interface{}
+	newChart := func(match string) *chart.Chart {
+		top := &chart.Chart{
+			Metadata: &chart.Metadata{
+				Name:    "top",
+				Version: "0.1.0",
+				Dependencies: []*chart.Dependency{
+					{Name: "feature", Version: "0.1.0", Condition: "features.*.enabled", ConditionMatch: match},
+				},
+			},
+			Values: M{},
+		}
+		top.AddDependency(&chart.Chart{Metadata: &chart.Metadata{Name: "feature", Version: "0.1.0"}})
+		return top
+	}
+	mixed := M{"features": M{
+		"alpha": M{"enabled": true},
+		"beta":  M{"enabled": false},
+		"gamma": M{"enabled": "yes"},
+		"delta": "unrelated",
+	}}
+
+	tests := []struct {
+		name  string
+		match string
+		v     M
+		e     []string
+	}{{
+		"any with mixed values",
+		"",
+		mixed,
+		[]string{"top", "top.feature"},
+	}, {
+		"all with mixed values",
+		chart.ConditionMatchAll,
+		mixed,
+		[]string{"top"},
+	}, {
+		"all with true values",
+		chart.ConditionMatchAll,
+		M{"features": M{"alpha": M{"enabled": true}, "beta": M{"enabled": true}}},
+		[]string{"top", "top.feature"},
+	}, {
+		"any with false values",
+		chart.ConditionMatchAny,
+		M{"features": M{"alpha": M{"enabled": false}, "beta": M{"enabled": false}}},
+		[]string{"top"},
+	}, {
+		"no matching values",
+		chart.ConditionMatchAll,
+		M{"features": M{"alpha": M{"replicas": 2}}},
+		[]string{"top", "top.feature"},
+	}}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			c := newChart(tc.match)
+			if err := processDependencyEnabled(c, tc.v, "", nil, nil); err != nil {
+				t.Fatalf("error processing enabled dependencies %v", err)
+			}
+			names := extractChartNames(c)
+			if strings.Join(names, ",") != strings.Join(tc.e, ",") {
+				t.Errorf("got %v, expected %v", names, tc.e)
+			}
+		})
+	}
+}
+
+func TestDependencyEnabledAliasDisabled(t *testing.T) {
+	type M = map[string]interface{}
+	newChart := func() *chart.Chart {
+		top := &chart.Chart{
+			Metadata: &chart.Metadata{
+				Name:    "top",
+				Version: "0.1.0",
+				Dependencies: []*chart.Dependency{
+					{Name: "db", Version: "0.1.0", Condition: "db.enabled"},
+					{Name: "db", Version: "0.1.0", Alias: "replica", Condition: "replica.enabled"},
+				},
+			},
+			Values: M{},
+		}
+		top.AddDependency(&chart.Chart{Metadata: &chart.Metadata{Name: "db", Version: "0.1.0"}})
+		return top
+	}
+
+	tests := []struct {
+		name string
+		v    M
+		e    []string
+	}{{
+		"alias disabled",
+		M{"db": M{"enabled": true}, "replica": M{"enabled": false}},
+		[]string{"top", "top.db"},
+	}, {
+		"original disabled",
+		M{"db": M{"enabled": false}, "replica": M{"enabled": true}},
+		[]string{"top", "top.replica"},
+	}}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			c := newChart()
+			if err := processDependencyEnabled(c, tc.v, "", nil, nil); err != nil {
+				t.Fatalf("error processing enabled dependencies %v", err)
+			}
+			names := extractChartNames(c)
+			if strings.Join(names, ",") != strings.Join(tc.e, ",") {
+				t.Errorf("got %v, expected %v", names, tc.e)
+			}
+			if l := len(c.Metadata.Dependencies); l != 1 {
+				t.Errorf("expected one dependency in the metadata, got %d", l)
+			}
+		})
+	}
+}
+
 // extractCharts recursively searches chart dependencies returning all charts found
 func extractChartNames(c *chart.Chart) []string {
 	var out []string
@@ -212,7 +393,7 @@ func TestProcessDependencyImportValues(t *testing.T) {
 	e["SCBexported2A"] = "blaster"
 	e["global.SC1exported2.all.SC1exported3"] = "SC1expstr"
 
-	if err := processDependencyImportValues(c); err != nil {
+	if err := processDependencyImportValues(c, false); err != nil {
 		t.Fatalf("processing import values dependencies %v", err)
 	}
 	cc := Values(c.Values)
@@ -239,11 +420,67 @@ func TestProcessDependencyImportValues(t *testing.T) {
 	}
 }
 
+func TestProcessDependencyImportValuesOrder(t *testing.T) {
+	newChart := func(order ...string) *chart.Chart {
+		c := &chart.Chart{
+			Metadata: &chart.Metadata{Name: "parent"},
+			Values:   map[string]interface{}{},
+		}
+		for _, name := range order {
+			c.Metadata.Dependencies = append(c.Metadata.Dependencies, &chart.Dependency{
+				Name: name,
+				ImportValues: []interface{}{
+					map[string]interface{}{"child": "data", "parent": "shared"},
+					"defaults",
+				},
+			})
+			c.AddDependency(&chart.Chart{
+				Metadata: &chart.Metadata{Name: name},
+				Values: map[string]interface{}{
+					"data": map[string]interface{}{"owner": name, name: true},
+					"exports": map[string]interface{}{
+						"defaults": map[string]interface{}{"exportedBy": name},
+					},
+				},
+			})
+		}
+		return c
+	}
+
+	for _, order := range [][]string{{"first", "second"}, {"second", "first"}} {
+		// Repeat to make sure the result does not depend on map iteration order
+		for i := 0; i < 10; i++ {
+			c := newChart(order...)
+			if err := processDependencyImportValues(c, false); err != nil {
+				t.Fatalf("processing import values dependencies %v", err)
+			}
+			last := order[len(order)-1]
+			cc := Values(c.Values)
+
+			for path, expect := range map[string]interface{}{
+				"shared.owner":     last,
+				"shared.first":     true,
+				"shared.second":    true,
+				"exportedBy":       last,
+				"first.data.owner": "first",
+			} {
+				v, err := cc.PathValue(path)
+				if err != nil {
+					t.Fatalf("retrieving %s for order %v: %v", path, order, err)
+				}
+				if v != expect {
+					t.Errorf("expected %s to be %v for order %v, got %v", path, expect, order, v)
+				}
+			}
+		}
+	}
+}
+
 func TestProcessDependencyImportValuesForEnabledCharts(t *testing.T) {
 	c := loadChart(t, "testdata/import-values-from-enabled-subchart/parent-chart")
 	nameOverride := "parent-chart-prod"
 
-	if err := processDependencyImportValues(c); err != nil {
+	if err := processDependencyImportValues(c, false); err != nil {
 		t.Fatalf("processing import values dependencies %v", err)
 	}
 
@@ -251,7 +488,7 @@ func TestProcessDependencyImportValuesForEnabledCharts(t *testing.T) {
 		t.Fatalf("expected 2 dependencies for this chart, but got %d", len(c.Dependencies()))
 	}
 
-	if err := processDependencyEnabled(c, c.Values, ""); err != nil {
+	if err := processDependencyEnabled(c, c.Values, "", nil, nil); err != nil {
 		t.Fatalf("expected no errors but got %q", err)
 	}
 
@@ -269,6 +506,43 @@ func TestProcessDependencyImportValuesForEnabledCharts(t *testing.T) {
 	}
 }
 
+func TestProcessDependenciesWithOptionsStrict(t *testing.T) {
+	type M = map[string]interface{}
+	newChart := func() *chart.Chart {
+		top := &chart.Chart{
+			Metadata: &chart.Metadata{
+				Name:    "top",
+				Version: "0.1.0",
+				Dependencies: []*chart.Dependency{{
+					Name:         "child",
+					Version:      "0.1.0",
+					ImportValues: []interface{}{"data", M{"child": "setings", "parent": "settings"}},
+				}},
+			},
+			Values: M{},
+		}
+		top.AddDependency(&chart.Chart{
+			Metadata: &chart.Metadata{Name: "child", Version: "0.1.0"},
+			Values:   M{"exports": M{"data": M{"port": 80}}, "settings": M{"debug": true}},
+		})
+		return top
+	}
+
+	c := newChart()
+	if err := ProcessDependencies(c, nil); err != nil {
+		t.Fatalf("expected a missing import to be ignored, got %v", err)
+	}
+	if _, ok := c.Values["port"]; !ok {
+		t.Errorf("expected the exported values to be imported, got %v", c.Values)
+	}
+
+	_, err := ProcessDependenciesWithOptions(newChart(), nil, DependencyOptions{Strict: true})
+	expected := `import-values of chart child: "setings" is not a table`
+	if err == nil || err.Error() != expected {
+		t.Errorf("expected %q, got %v", expected, err)
+	}
+}
+
 func TestGetAliasDependency(t *testing.T) {
 	c := loadChart(t, "testdata/frobnitz")
 	req := c.Metadata.Dependencies
@@ -316,7 +590,7 @@ func TestDependentChartAliases(t *testing.T) {
 		t.Fatalf("expected 2 dependencies for this chart, but got %d", len(c.Dependencies()))
 	}
 
-	if err := processDependencyEnabled(c, c.Values, ""); err != nil {
+	if err := processDependencyEnabled(c, c.Values, "", nil, nil); err != nil {
 		t.Fatalf("expected no errors but got %q", err)
 	}
 
@@ -355,7 +629,7 @@ func TestDependentChartWithSubChartsAbsentInDependency(t *testing.T) {
 		t.Fatalf("expected 2 dependencies for this chart, but got %d", len(c.Dependencies()))
 	}
 
-	if err := processDependencyEnabled(c, c.Values, ""); err != nil {
+	if err := processDependencyEnabled(c, c.Values, "", nil, nil); err != nil {
 		t.Fatalf("expected no errors but got %q", err)
 	}
 
@@ -392,7 +666,7 @@ func TestDependentChartsWithSubchartsAllSpecifiedInDependency(t *testing.T) {
 		t.Fatalf("expected 2 dependencies for this chart, but got %d", len(c.Dependencies()))
 	}
 
-	if err := processDependencyEnabled(c, c.Values, ""); err != nil {
+	if err := processDependencyEnabled(c, c.Values, "", nil, nil); err != nil {
 		t.Fatalf("expected no errors but got %q", err)
 	}
 
@@ -412,7 +686,7 @@ func TestDependentChartsWithSomeSubchartsSpecifiedInDependency(t *testing.T) {
 		t.Fatalf("expected 2 dependencies for this chart, but got %d", len(c.Dependencies()))
 	}
 
-	if err := processDependencyEnabled(c, c.Values, ""); err != nil {
+	if err := processDependencyEnabled(c, c.Values, "", nil, nil); err != nil {
 		t.Fatalf("expected no errors but got %q", err)
 	}
 
@@ -424,3 +698,189 @@ func TestDependentChartsWithSomeSubchartsSpecifiedInDependency(t *testing.T) {
 		t.Fatalf("expected 1 dependency specified in Chart.yaml, got %d", len(c.Metadata.Dependencies))
 	}
 }
+
+func TestExplainDependency(t *testing.T) {
+	c := loadChart(t, "testdata/subpop")
+
+	tests := []struct {
+		name   string
+		expect string
+	}{{
+		"subchartb",
+		`subchartb is required by subchart1 (parentchart > subchart1 > subchartb), version constraint "0.1.0", condition "subchartb.enabled", tags ["front-end" "subchartb"]
+subchartb is required by subchart2 (parentchart > subchart2 > subchartb), version constraint "0.1.0", condition "subchartb.enabled", tags ["back-end" "subchartb"]`,
+	}, {
+		"subchart2alias",
+		`subchart2 is required by parentchart (parentchart > subchart2) as alias "subchart2alias", version constraint "0.1.0", condition "subchart2alias.enabled"`,
+	}}
+
+	for _, tt := range tests {
+		got, err := ExplainDependency(c, tt.name)
+		if err != nil {
+			t.Fatalf("unexpected error explaining %s: %s", tt.name, err)
+		}
+		if got != tt.expect {
+			t.Errorf("explaining %s:\nexpected: %s\ngot:      %s", tt.name, tt.expect, got)
+		}
+	}
+
+	got, err := ExplainDependency(c, "subchart2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := len(strings.Split(got, "\n")); n != 2 {
+		t.Errorf("expected both requirements of subchart2 to be explained, got %d:\n%s", n, got)
+	}
+
+	if _, err := ExplainDependency(c, "missing"); err == nil {
+		t.Error("expected an error for a dependency that is not part of the chart")
+	}
+}
+
+func TestProcessDependenciesWithOptionsProgress(t *testing.T) {
+	c := loadChart(t, "testdata/subpop")
+	v := map[string]interface{}{"tags": map[string]interface{}{"front-end": false, "back-end": true}}
+
+	var events []DependencyEvent
+	progress := func(e DependencyEvent) { events = append(events, e) }
+	if _, err := ProcessDependenciesWithOptions(c, v, DependencyOptions{Progress: progress}); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []DependencyEvent{
+		{Path: "subchart1", Name: "subchart1", Version: "0.1.0", State: DependencyDisabled, Reason: DependencyReasonTag},
+		{Path: "subchart2", Name: "subchart2", Version: "0.1.0", State: DependencyEnabled, Reason: DependencyReasonTag},
+		{Path: "subchart2alias", Name: "subchart2alias", Version: "0.1.0", State: DependencyDisabled, Reason: DependencyReasonCondition},
+		{Path: "subchart2.subchartb", Name: "subchartb", Version: "0.1.0", State: DependencyEnabled, Reason: DependencyReasonTag},
+		{Path: "subchart2.subchartc", Name: "subchartc", Version: "0.1.0", State: DependencyEnabled, Reason: DependencyReasonTag},
+	}
+	if !reflect.DeepEqual(events, expected) {
+		t.Errorf("expected events:\n%+v\ngot:\n%+v", expected, events)
+	}
+}
+
+func TestProcessDependenciesWithOptionsDisabledByCondition(t *testing.T) {
+	c := loadChart(t, "testdata/subpop")
+	v := map[string]interface{}{
+		"subchart1": map[string]interface{}{"enabled": false},
+		"tags":      map[string]interface{}{"back-end": true},
+	}
+
+	var events []DependencyEvent
+	progress := func(e DependencyEvent) { events = append(events, e) }
+	if _, err := ProcessDependenciesWithOptions(c, v, DependencyOptions{Progress: progress}); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := DependencyEvent{Path: "subchart1", Name: "subchart1", Version: "0.1.0", State: DependencyDisabled, Reason: DependencyReasonCondition}
+	if len(events) == 0 || events[0] != expected {
+		t.Errorf("expected the first event to be %+v, got %+v", expected, events)
+	}
+}
+
+func TestProcessDependenciesVersionMismatch(t *testing.T) {
+	c := &chart.Chart{
+		Metadata: &chart.Metadata{
+			APIVersion: chart.APIVersionV2,
+			Name:       "parent",
+			Version:    "0.1.0",
+			Dependencies: []*chart.Dependency{
+				{Name: "db", Version: ">=2.0"},
+				{Name: "cache", Version: "^1.0.0"},
+			},
+		},
+	}
+	c.AddDependency(&chart.Chart{Metadata: &chart.Metadata{Name: "db", Version: "1.4"}})
+	c.AddDependency(&chart.Chart{Metadata: &chart.Metadata{Name: "cache", Version: "1.2.0"}})
+
+	warnings, err := ProcessDependenciesWithOptions(c, nil, DependencyOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{"dependency db: requested >=2.0 but found 1.4"}
+	if !reflect.DeepEqual(warnings, expected) {
+		t.Errorf("expected warnings %q, got %q", expected, warnings)
+	}
+	if l := len(c.Dependencies()); l != 2 {
+		t.Errorf("expected the mismatched chart to be kept as an extra chart, got %d charts", l)
+	}
+}
+
+func TestProcessDependenciesCycle(t *testing.T) {
+	a := &chart.Chart{Metadata: &chart.Metadata{Name: "a", Version: "0.1.0"}}
+	b := &chart.Chart{Metadata: &chart.Metadata{Name: "b", Version: "0.1.0"}}
+	top := &chart.Chart{Metadata: &chart.Metadata{Name: "top", Version: "0.1.0"}}
+	top.AddDependency(a)
+	a.AddDependency(b)
+	b.AddDependency(a)
+
+	err := ProcessDependencies(top, nil)
+	expected := "circular dependency detected: a -> b -> a"
+	if err == nil || err.Error() != expected {
+		t.Errorf("expected %q, got %v", expected, err)
+	}
+}
+
+func TestProcessDependenciesWithOptionsMissing(t *testing.T) {
+	c := &chart.Chart{
+		Metadata: &chart.Metadata{
+			APIVersion: chart.APIVersionV2,
+			Name:       "parent",
+			Version:    "0.1.0",
+			Dependencies: []*chart.Dependency{
+				{Name: "ghost", Version: "1.0.0"},
+			},
+		},
+	}
+
+	var events []DependencyEvent
+	progress := func(e DependencyEvent) { events = append(events, e) }
+	if _, err := ProcessDependenciesWithOptions(c, nil, DependencyOptions{Progress: progress}); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []DependencyEvent{{Path: "ghost", Name: "ghost", Version: "1.0.0", State: DependencyMissing, Reason: DependencyReasonDefault}}
+	if !reflect.DeepEqual(events, expected) {
+		t.Errorf("expected events %+v, got %+v", expected, events)
+	}
+}
+
+func TestListDependencies(t *testing.T) {
+	sub := func(name, version string) *chart.Chart {
+		return &chart.Chart{Metadata: &chart.Metadata{APIVersion: chart.APIVersionV2, Name: name, Version: version}}
+	}
+	c := &chart.Chart{
+		Metadata: &chart.Metadata{
+			APIVersion: chart.APIVersionV2,
+			Name:       "parent",
+			Version:    "0.1.0",
+			Dependencies: []*chart.Dependency{
+				{Name: "postgresql", Version: "~10.3.0", Repository: "https://charts.example.com"},
+				{Name: "redis", Version: "^14.0.0", Repository: "https://charts.example.com"},
+				{Name: "common", Version: ">=1.0.0 <2.0.0", Alias: "lib"},
+				{Name: "ghost", Version: "1.0.0"},
+			},
+		},
+	}
+	c.AddDependency(sub("postgresql", "10.3.5"), sub("redis", "12.1.0"), sub("common", "0.9.0"), sub("common", "1.4.0"))
+
+	statuses, err := ListDependencies(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []DependencyStatus{
+		{Name: "postgresql", Constraint: "~10.3.0", Repository: "https://charts.example.com", Present: true, Version: "10.3.5", Satisfied: true},
+		{Name: "redis", Constraint: "^14.0.0", Repository: "https://charts.example.com", Present: true, Version: "12.1.0"},
+		{Name: "common", Alias: "lib", Constraint: ">=1.0.0 <2.0.0", Present: true, Version: "1.4.0", Satisfied: true},
+		{Name: "ghost", Constraint: "1.0.0"},
+	}
+	if !reflect.DeepEqual(statuses, expected) {
+		t.Errorf("expected statuses:\n%+v\ngot:\n%+v", expected, statuses)
+	}
+
+	c.Metadata.Dependencies = append(c.Metadata.Dependencies, &chart.Dependency{Name: "bad", Version: "not a version"})
+	if _, err := ListDependencies(c); err == nil || !strings.Contains(err.Error(), `dependency "bad" has an invalid version constraint`) {
+		t.Errorf("expected an invalid constraint error, got %v", err)
+	}
+}
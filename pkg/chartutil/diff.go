@@ -0,0 +1,248 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chartutil
+
+import (
+	"bytes"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"helm.sh/helm/v3/pkg/chart"
+)
+
+// ChartDiff describes the differences between two charts, usually two versions
+// of the same chart.
+type ChartDiff struct {
+	// Metadata lists the Chart.yaml fields that differ.
+	Metadata []MetadataChange
+	// Templates lists the templates that were added, removed or changed.
+	Templates FileChanges
+	// Files lists the other chart files that were added, removed or changed.
+	Files FileChanges
+	// Values lists the default values that differ, by dotted path.
+	Values []ValueChange
+	// Dependencies lists the declared dependencies that were added, removed
+	// or that changed version or repository.
+	Dependencies []DependencyChange
+}
+
+// MetadataChange is a Chart.yaml field that differs between two charts.
+type MetadataChange struct {
+	Field string
+	Old   string
+	New   string
+}
+
+// FileChanges holds the names of the files that differ between two charts.
+type FileChanges struct {
+	Added   []string
+	Removed []string
+	Changed []string
+}
+
+// ValueChange is a default value that differs between two charts. Old is nil
+// when the value was added, and New is nil when it was removed.
+type ValueChange struct {
+	Path string
+	Old  interface{}
+	New  interface{}
+}
+
+// DependencyChange is a declared dependency that differs between two charts.
+// The old fields are empty when the dependency was added, and the new fields
+// are empty when it was removed.
+type DependencyChange struct {
+	Name          string
+	OldVersion    string
+	NewVersion    string
+	OldRepository string
+	NewRepository string
+}
+
+// Empty reports whether no differences were found.
+func (d ChartDiff) Empty() bool {
+	return len(d.Metadata) == 0 &&
+		d.Templates.empty() &&
+		d.Files.empty() &&
+		len(d.Values) == 0 &&
+		len(d.Dependencies) == 0
+}
+
+func (f FileChanges) empty() bool {
+	return len(f.Added) == 0 && len(f.Removed) == 0 && len(f.Changed) == 0
+}
+
+// DiffCharts compares chart a to chart b and reports what changed in b.
+//
+// Dependencies are compared as declared in Chart.yaml (or requirements.yaml
+// for apiVersion v1 charts), keyed by their alias when one is set.
+func DiffCharts(a, b *chart.Chart) (ChartDiff, error) {
+	var diff ChartDiff
+	if a == nil || b == nil {
+		return diff, errors.New("cannot diff a nil chart")
+	}
+	if a.Metadata == nil || b.Metadata == nil {
+		return diff, errors.New("cannot diff a chart without metadata")
+	}
+
+	diff.Metadata = diffMetadata(a.Metadata, b.Metadata)
+	diff.Templates = diffFiles(a.Templates, b.Templates)
+	diff.Files = diffFiles(a.Files, b.Files)
+	diffValues("", a.Values, b.Values, &diff.Values)
+	sort.Slice(diff.Values, func(i, j int) bool { return diff.Values[i].Path < diff.Values[j].Path })
+	diff.Dependencies = diffDependencies(a.Metadata.Dependencies, b.Metadata.Dependencies)
+	return diff, nil
+}
+
+func diffMetadata(a, b *chart.Metadata) []MetadataChange {
+	fields := []struct {
+		name     string
+		old, new string
+	}{
+		{"apiVersion", a.APIVersion, b.APIVersion},
+		{"name", a.Name, b.Name},
+		{"version", a.Version, b.Version},
+		{"appVersion", a.AppVersion, b.AppVersion},
+		{"kubeVersion", a.KubeVersion, b.KubeVersion},
+		{"description", a.Description, b.Description},
+		{"type", a.Type, b.Type},
+		{"home", a.Home, b.Home},
+		{"icon", a.Icon, b.Icon},
+		{"deprecated", strconv.FormatBool(a.Deprecated), strconv.FormatBool(b.Deprecated)},
+	}
+
+	var changes []MetadataChange
+	for _, f := range fields {
+		if f.old != f.new {
+			changes = append(changes, MetadataChange{Field: f.name, Old: f.old, New: f.new})
+		}
+	}
+	return changes
+}
+
+func diffFiles(a, b []*chart.File) FileChanges {
+	var changes FileChanges
+	old := make(map[string][]byte, len(a))
+	for _, f := range a {
+		old[f.Name] = f.Data
+	}
+	seen := make(map[string]bool, len(b))
+	for _, f := range b {
+		seen[f.Name] = true
+		data, ok := old[f.Name]
+		switch {
+		case !ok:
+			changes.Added = append(changes.Added, f.Name)
+		case !bytes.Equal(data, f.Data):
+			changes.Changed = append(changes.Changed, f.Name)
+		}
+	}
+	for _, f := range a {
+		if !seen[f.Name] {
+			changes.Removed = append(changes.Removed, f.Name)
+		}
+	}
+	sort.Strings(changes.Added)
+	sort.Strings(changes.Removed)
+	sort.Strings(changes.Changed)
+	return changes
+}
+
+// diffValues walks both value trees and records the leaves that differ. Lists
+// are compared as a whole.
+func diffValues(prefix string, a, b map[string]interface{}, changes *[]ValueChange) {
+	for key, oldVal := range a {
+		path := joinValuePath(prefix, key)
+		newVal, ok := b[key]
+		if !ok {
+			*changes = append(*changes, ValueChange{Path: path, Old: oldVal})
+			continue
+		}
+		oldMap, oldIsMap := asValueMap(oldVal)
+		newMap, newIsMap := asValueMap(newVal)
+		if oldIsMap && newIsMap {
+			diffValues(path, oldMap, newMap, changes)
+			continue
+		}
+		if !reflect.DeepEqual(oldVal, newVal) {
+			*changes = append(*changes, ValueChange{Path: path, Old: oldVal, New: newVal})
+		}
+	}
+	for key, newVal := range b {
+		if _, ok := a[key]; !ok {
+			*changes = append(*changes, ValueChange{Path: joinValuePath(prefix, key), New: newVal})
+		}
+	}
+}
+
+func asValueMap(v interface{}) (map[string]interface{}, bool) {
+	switch m := v.(type) {
+	case map[string]interface{}:
+		return m, true
+	case Values:
+		return m, true
+	}
+	return nil, false
+}
+
+func joinValuePath(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return strings.Join([]string{prefix, key}, ".")
+}
+
+func diffDependencies(a, b []*chart.Dependency) []DependencyChange {
+	key := func(d *chart.Dependency) string {
+		if d.Alias != "" {
+			return d.Alias
+		}
+		return d.Name
+	}
+
+	old := make(map[string]*chart.Dependency, len(a))
+	for _, d := range a {
+		old[key(d)] = d
+	}
+
+	var changes []DependencyChange
+	seen := make(map[string]bool, len(b))
+	for _, d := range b {
+		name := key(d)
+		seen[name] = true
+		change := DependencyChange{Name: name, NewVersion: d.Version, NewRepository: d.Repository}
+		if o, ok := old[name]; ok {
+			if o.Version == d.Version && o.Repository == d.Repository {
+				continue
+			}
+			change.OldVersion = o.Version
+			change.OldRepository = o.Repository
+		}
+		changes = append(changes, change)
+	}
+	for _, d := range a {
+		if name := key(d); !seen[name] {
+			changes = append(changes, DependencyChange{Name: name, OldVersion: d.Version, OldRepository: d.Repository})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Name < changes[j].Name })
+	return changes
+}
@@ -0,0 +1,114 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chartutil
+
+import (
+	"reflect"
+	"testing"
+
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chart/loader"
+)
+
+func TestDiffCharts(t *testing.T) {
+	a, err := loader.Load("testdata/frobnitz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := loader.Load("testdata/frobnitz")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	diff, err := DiffCharts(a, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !diff.Empty() {
+		t.Fatalf("Expected no differences between identical charts, got %+v", diff)
+	}
+
+	b.Metadata.Version = "1.2.4"
+	b.Templates[0].Data = append([]byte("# changed\n"), b.Templates[0].Data...)
+	b.Files = append(b.Files, &chart.File{Name: "docs/upgrading.md", Data: []byte("# Upgrading")})
+	b.Metadata.Dependencies[0].Version = "0.2.0"
+	b.Values["name"] = "my-frobnitz"
+	b.Values["extra"] = true
+
+	diff, err = DiffCharts(a, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := []MetadataChange{{Field: "version", Old: "1.2.3", New: "1.2.4"}}; !reflect.DeepEqual(diff.Metadata, expected) {
+		t.Errorf("Expected metadata changes %v, got %v", expected, diff.Metadata)
+	}
+	if expected := (FileChanges{Changed: []string{b.Templates[0].Name}}); !reflect.DeepEqual(diff.Templates, expected) {
+		t.Errorf("Expected template changes %+v, got %+v", expected, diff.Templates)
+	}
+	if expected := (FileChanges{Added: []string{"docs/upgrading.md"}}); !reflect.DeepEqual(diff.Files, expected) {
+		t.Errorf("Expected file changes %+v, got %+v", expected, diff.Files)
+	}
+	expectedDeps := []DependencyChange{{
+		Name:          "alpine",
+		OldVersion:    "0.1.0",
+		NewVersion:    "0.2.0",
+		OldRepository: "https://example.com/charts",
+		NewRepository: "https://example.com/charts",
+	}}
+	if !reflect.DeepEqual(diff.Dependencies, expectedDeps) {
+		t.Errorf("Expected dependency changes %+v, got %+v", expectedDeps, diff.Dependencies)
+	}
+	expectedValues := []ValueChange{
+		{Path: "extra", New: true},
+		{Path: "name", Old: "Some Name", New: "my-frobnitz"},
+	}
+	if !reflect.DeepEqual(diff.Values, expectedValues) {
+		t.Errorf("Expected value changes %+v, got %+v", expectedValues, diff.Values)
+	}
+}
+
+func TestDiffValuesNested(t *testing.T) {
+	a := map[string]interface{}{
+		"image": map[string]interface{}{"repository": "nginx", "tag": "1.19"},
+		"ports": []interface{}{80},
+		"old":   "gone",
+	}
+	b := map[string]interface{}{
+		"image": map[string]interface{}{"repository": "nginx", "tag": "1.21"},
+		"ports": []interface{}{80, 443},
+	}
+
+	diff, err := DiffCharts(&chart.Chart{Metadata: &chart.Metadata{}, Values: a}, &chart.Chart{Metadata: &chart.Metadata{}, Values: b})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []ValueChange{
+		{Path: "image.tag", Old: "1.19", New: "1.21"},
+		{Path: "old", Old: "gone"},
+		{Path: "ports", Old: []interface{}{80}, New: []interface{}{80, 443}},
+	}
+	if !reflect.DeepEqual(diff.Values, expected) {
+		t.Errorf("Expected value changes %+v, got %+v", expected, diff.Values)
+	}
+}
+
+func TestDiffChartsNil(t *testing.T) {
+	if _, err := DiffCharts(nil, &chart.Chart{}); err == nil {
+		t.Error("Expected an error when diffing a nil chart")
+	}
+}
@@ -0,0 +1,109 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chartutil
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"math"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+// CanonicalHash returns a hex encoded SHA-256 of the values that only depends
+// on their content. Map keys are serialized in sorted order and numbers are
+// normalized, so that 80, int64(80) and 80.0 hash the same, which makes values
+// parsed from YAML, JSON and --set flags comparable.
+func CanonicalHash(v Values) (string, error) {
+	b, err := json.Marshal(canonicalize(map[string]interface{}(v)))
+	if err != nil {
+		return "", errors.Wrap(err, "unable to serialize values")
+	}
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// canonicalize returns a copy of v in which nested tables are plain maps and
+// numbers are json.Numbers in a single format. encoding/json already sorts map
+// keys.
+func canonicalize(v interface{}) interface{} {
+	switch v := v.(type) {
+	case Values:
+		return canonicalize(map[string]interface{}(v))
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for key, val := range v {
+			out[key] = canonicalize(val)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, val := range v {
+			out[i] = canonicalize(val)
+		}
+		return out
+	case int:
+		return json.Number(strconv.FormatInt(int64(v), 10))
+	case int8:
+		return json.Number(strconv.FormatInt(int64(v), 10))
+	case int16:
+		return json.Number(strconv.FormatInt(int64(v), 10))
+	case int32:
+		return json.Number(strconv.FormatInt(int64(v), 10))
+	case int64:
+		return json.Number(strconv.FormatInt(v, 10))
+	case uint:
+		return json.Number(strconv.FormatUint(uint64(v), 10))
+	case uint8:
+		return json.Number(strconv.FormatUint(uint64(v), 10))
+	case uint16:
+		return json.Number(strconv.FormatUint(uint64(v), 10))
+	case uint32:
+		return json.Number(strconv.FormatUint(uint64(v), 10))
+	case uint64:
+		return json.Number(strconv.FormatUint(v, 10))
+	case float32:
+		return canonicalFloat(float64(v))
+	case float64:
+		return canonicalFloat(v)
+	case json.Number:
+		if f, err := v.Float64(); err == nil {
+			if i, err := v.Int64(); err == nil {
+				return json.Number(strconv.FormatInt(i, 10))
+			}
+			return canonicalFloat(f)
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+// canonicalFloat formats whole numbers like integers and every other number in
+// its shortest form. NaN and infinities are left alone, as they cannot be
+// serialized.
+func canonicalFloat(f float64) interface{} {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return f
+	}
+	if f == math.Trunc(f) && math.Abs(f) < 1<<53 {
+		return json.Number(strconv.FormatInt(int64(f), 10))
+	}
+	return json.Number(strconv.FormatFloat(f, 'g', -1, 64))
+}
@@ -0,0 +1,86 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chartutil
+
+import (
+	"math"
+	"testing"
+)
+
+func TestCanonicalHash(t *testing.T) {
+	hash := func(v Values) string {
+		t.Helper()
+		h, err := CanonicalHash(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return h
+	}
+
+	fromYAML, err := ReadValues([]byte(`
+name: web
+replicas: 3
+ratio: 0.5
+image:
+  repository: nginx
+  tag: "1.21"
+ports: [80, 443]
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	reordered := Values{
+		"ports": []interface{}{int64(80), 443},
+		"image": map[string]interface{}{
+			"tag":        "1.21",
+			"repository": "nginx",
+		},
+		"ratio":    float32(0.5),
+		"replicas": int64(3),
+		"name":     "web",
+	}
+
+	h := hash(fromYAML)
+	if len(h) != 64 {
+		t.Errorf("Expected a hex encoded SHA-256, got %q", h)
+	}
+	if other := hash(reordered); other != h {
+		t.Errorf("Expected equal values to hash the same, got %s and %s", h, other)
+	}
+	for i := 0; i < 10; i++ {
+		if other := hash(fromYAML); other != h {
+			t.Fatalf("Expected the hash to be stable, got %s and %s", h, other)
+		}
+	}
+
+	changed := Values{}
+	for k, v := range reordered {
+		changed[k] = v
+	}
+	changed["image"] = map[string]interface{}{"tag": "1.22", "repository": "nginx"}
+	if other := hash(changed); other == h {
+		t.Error("Expected a changed value to hash differently")
+	}
+
+	if hash(Values{"a": "1"}) == hash(Values{"a": 1}) {
+		t.Error("Expected a string and a number to hash differently")
+	}
+
+	if _, err := CanonicalHash(Values{"bad": math.NaN()}); err == nil {
+		t.Error("Expected an error for values that cannot be serialized")
+	}
+}
@@ -18,7 +18,11 @@ package chartutil
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
+	"math"
+	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/pkg/errors"
@@ -85,3 +89,174 @@ func ValidateAgainstSingleSchema(values Values, schemaJSON []byte) error {
 
 	return nil
 }
+
+// coerceToSchemas converts the string values of the chart and its subcharts
+// to the scalar types their schemas declare.
+func coerceToSchemas(chrt *chart.Chart, values map[string]interface{}) error {
+	if chrt.Schema != nil {
+		var schema map[string]interface{}
+		if err := json.Unmarshal(chrt.Schema, &schema); err != nil {
+			return errors.Wrapf(err, "unable to parse the values schema of chart %s", chrt.Name())
+		}
+		coerceToSchema(values, schema)
+	}
+	for _, subchart := range chrt.Dependencies() {
+		if subchartValues, ok := values[subchart.Name()].(map[string]interface{}); ok {
+			if err := coerceToSchemas(subchart, subchartValues); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// coerceToSchema returns value converted to the scalar type the schema
+// declares for it, descending into the properties of objects and the items
+// of arrays.
+func coerceToSchema(value interface{}, schema map[string]interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		properties, _ := schema["properties"].(map[string]interface{})
+		for key, val := range v {
+			if propSchema, ok := properties[key].(map[string]interface{}); ok {
+				v[key] = coerceToSchema(val, propSchema)
+			}
+		}
+	case []interface{}:
+		if items, ok := schema["items"].(map[string]interface{}); ok {
+			for i, item := range v {
+				v[i] = coerceToSchema(item, items)
+			}
+		}
+	case string:
+		return coerceString(v, schemaTypes(schema))
+	}
+	return value
+}
+
+// schemaTypes returns the types a schema allows, whether its type is a single
+// name or a list of them.
+func schemaTypes(schema map[string]interface{}) []string {
+	switch t := schema["type"].(type) {
+	case string:
+		return []string{t}
+	case []interface{}:
+		types := make([]string, 0, len(t))
+		for _, name := range t {
+			if s, ok := name.(string); ok {
+				types = append(types, s)
+			}
+		}
+		return types
+	}
+	return nil
+}
+
+// coerceString converts s to the first of types it can be parsed as, unless
+// the types allow a string.
+func coerceString(s string, types []string) interface{} {
+	for _, t := range types {
+		if t == "string" {
+			return s
+		}
+	}
+	for _, t := range types {
+		switch t {
+		case "integer":
+			if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+				return i
+			}
+		case "number":
+			if f, err := strconv.ParseFloat(s, 64); err == nil {
+				return f
+			}
+		case "boolean":
+			if s == "true" || s == "false" {
+				return s == "true"
+			}
+		}
+	}
+	return s
+}
+
+// GenerateSchema infers a starter JSON schema from a chart's default values.
+//
+// Every value gets the type it has in values and every key with a non-null
+// value is required. Additional properties are allowed so the schema only
+// catches values of the wrong type. The result is meant to be refined by hand
+// and saved as values.schema.json.
+func GenerateSchema(values Values) ([]byte, error) {
+	schema := schemaFor(map[string]interface{}(values))
+	schema["$schema"] = "http://json-schema.org/draft-07/schema#"
+	return json.MarshalIndent(schema, "", "  ")
+}
+
+func schemaFor(value interface{}) map[string]interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		properties := make(map[string]interface{}, len(v))
+		var required []string
+		for key, val := range v {
+			properties[key] = schemaFor(val)
+			if val != nil {
+				required = append(required, key)
+			}
+		}
+		schema := map[string]interface{}{
+			"type":                 "object",
+			"properties":           properties,
+			"additionalProperties": true,
+		}
+		if len(required) > 0 {
+			sort.Strings(required)
+			schema["required"] = required
+		}
+		return schema
+	case []interface{}:
+		schema := map[string]interface{}{"type": "array"}
+		if items := itemsSchema(v); items != nil {
+			schema["items"] = items
+		}
+		return schema
+	case string:
+		return map[string]interface{}{"type": "string"}
+	case bool:
+		return map[string]interface{}{"type": "boolean"}
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return map[string]interface{}{"type": "integer"}
+	case float32:
+		return numberSchema(float64(v))
+	case float64:
+		return numberSchema(v)
+	}
+	// null, or a type that cannot be described, accepts anything
+	return map[string]interface{}{}
+}
+
+// numberSchema describes a float. Values read from YAML files hold all numbers
+// as float64, so whole numbers are taken to be integers.
+func numberSchema(f float64) map[string]interface{} {
+	if f == math.Trunc(f) {
+		return map[string]interface{}{"type": "integer"}
+	}
+	return map[string]interface{}{"type": "number"}
+}
+
+// itemsSchema returns the type shared by all items of a list, or nil if the
+// list is empty or its items differ in type. Objects and lists are not
+// described further, as their items rarely share the same keys.
+func itemsSchema(list []interface{}) map[string]interface{} {
+	if len(list) == 0 {
+		return nil
+	}
+	items := schemaFor(list[0])
+	for _, item := range list[1:] {
+		if schemaFor(item)["type"] != items["type"] {
+			return nil
+		}
+	}
+	if t := items["type"]; t == "object" || t == "array" {
+		return map[string]interface{}{"type": t}
+	}
+	return items
+}
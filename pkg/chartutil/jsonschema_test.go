@@ -18,6 +18,7 @@ package chartutil
 
 import (
 	"io/ioutil"
+	"strings"
 	"testing"
 
 	"helm.sh/helm/v3/pkg/chart"
@@ -141,3 +142,65 @@ func TestValidateAgainstSchemaNegative(t *testing.T) {
 		t.Errorf("Error string :\n`%s`\ndoes not match expected\n`%s`", errString, expectedErrString)
 	}
 }
+
+func TestGenerateSchema(t *testing.T) {
+	values, err := ReadValues([]byte(`
+replicaCount: 1
+ratio: 0.5
+image:
+  repository: nginx
+  pullPolicy: IfNotPresent
+  tag: null
+enabled: true
+ports: [80, 443]
+env:
+  - name: FOO
+    value: bar
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	schema, err := GenerateSchema(values)
+	if err != nil {
+		t.Fatalf("Error generating schema: %s", err)
+	}
+
+	if err := ValidateAgainstSingleSchema(values, schema); err != nil {
+		t.Errorf("Expected the source values to match the generated schema, got %s", err)
+	}
+
+	overrides := []map[string]interface{}{
+		{"replicaCount": 2, "image": map[string]interface{}{"tag": "1.0"}, "extra": "allowed"},
+		{"ports": []interface{}{8080}, "ratio": 1},
+	}
+	for _, override := range overrides {
+		vals := Values(CoalesceTables(override, copyValues(t, values)))
+		if err := ValidateAgainstSingleSchema(vals, schema); err != nil {
+			t.Errorf("Expected override %v to match the generated schema, got %s", override, err)
+		}
+	}
+
+	wrong := Values(CoalesceTables(map[string]interface{}{"replicaCount": "two", "image": map[string]interface{}{"pullPolicy": false}}, copyValues(t, values)))
+	err = ValidateAgainstSingleSchema(wrong, schema)
+	if err == nil {
+		t.Fatal("Expected wrong-typed overrides to be rejected")
+	}
+	for _, expect := range []string{
+		"- image.pullPolicy: Invalid type. Expected: string, given: boolean\n",
+		"- replicaCount: Invalid type. Expected: integer, given: string\n",
+	} {
+		if !strings.Contains(err.Error(), expect) {
+			t.Errorf("Expected %q in error:\n%s", expect, err)
+		}
+	}
+}
+
+func copyValues(t *testing.T, v Values) map[string]interface{} {
+	t.Helper()
+	c, err := copyTable(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
@@ -0,0 +1,123 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chartutil
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+var manifestSep = regexp.MustCompile("(?:^|\\s*\n)---\\s*")
+
+// ResourceID identifies a rendered Kubernetes resource.
+type ResourceID struct {
+	Kind      string
+	Namespace string
+	Name      string
+}
+
+func (r ResourceID) String() string {
+	if r.Namespace == "" {
+		return fmt.Sprintf("%s %q", r.Kind, r.Name)
+	}
+	return fmt.Sprintf("%s %q in namespace %q", r.Kind, r.Name, r.Namespace)
+}
+
+// DuplicateResource is a resource that is rendered more than once.
+type DuplicateResource struct {
+	ResourceID
+	// Templates lists the templates that render the resource, sorted and
+	// once for every time it is rendered.
+	Templates []string
+}
+
+func (d DuplicateResource) String() string {
+	return fmt.Sprintf("%s is rendered more than once, by %s", d.ResourceID, strings.Join(d.Templates, ", "))
+}
+
+// ResourceIndex records which templates rendered which resources.
+type ResourceIndex map[ResourceID][]string
+
+// Add records the resources found in the rendered manifest of a template.
+// Documents that cannot be parsed, or that have no kind or name, are skipped.
+func (idx ResourceIndex) Add(template, manifest string) {
+	for _, doc := range manifestSep.Split(manifest, -1) {
+		if strings.TrimSpace(doc) == "" {
+			continue
+		}
+		var obj struct {
+			Kind     string `json:"kind"`
+			Metadata struct {
+				Name      string `json:"name"`
+				Namespace string `json:"namespace"`
+			} `json:"metadata"`
+		}
+		if err := yaml.Unmarshal([]byte(doc), &obj); err != nil {
+			continue
+		}
+		if obj.Kind == "" || obj.Metadata.Name == "" {
+			continue
+		}
+		id := ResourceID{Kind: obj.Kind, Namespace: obj.Metadata.Namespace, Name: obj.Metadata.Name}
+		idx[id] = append(idx[id], template)
+	}
+}
+
+// Duplicates returns the resources that were rendered more than once, sorted
+// by kind, namespace and name.
+func (idx ResourceIndex) Duplicates() []DuplicateResource {
+	var dups []DuplicateResource
+	for id, templates := range idx {
+		if len(templates) > 1 {
+			templates = append([]string(nil), templates...)
+			sort.Strings(templates)
+			dups = append(dups, DuplicateResource{ResourceID: id, Templates: templates})
+		}
+	}
+	sort.Slice(dups, func(i, j int) bool {
+		a, b := dups[i].ResourceID, dups[j].ResourceID
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		return a.Name < b.Name
+	})
+	return dups
+}
+
+// FindDuplicateResources reports the resources that are rendered more than once
+// across the given manifests, which map template names to their rendered
+// content as returned by the rendering engine.
+func FindDuplicateResources(manifests map[string]string) []DuplicateResource {
+	names := make([]string, 0, len(manifests))
+	for name := range manifests {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	idx := ResourceIndex{}
+	for _, name := range names {
+		idx.Add(name, manifests[name])
+	}
+	return idx.Duplicates()
+}
@@ -0,0 +1,61 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chartutil
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFindDuplicateResources(t *testing.T) {
+	manifests := map[string]string{
+		"mychart/templates/service.yaml": `apiVersion: v1
+kind: Service
+metadata:
+  name: web
+`,
+		"mychart/templates/service-extra.yaml": `apiVersion: v1
+kind: Service
+metadata:
+  name: web
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+  namespace: other
+`,
+		"mychart/templates/deployment.yaml": `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+`,
+		"mychart/templates/NOTES.txt": "Thank you for installing web",
+	}
+
+	dups := FindDuplicateResources(manifests)
+	expected := []DuplicateResource{{
+		ResourceID: ResourceID{Kind: "Service", Name: "web"},
+		Templates:  []string{"mychart/templates/service-extra.yaml", "mychart/templates/service.yaml"},
+	}}
+	if !reflect.DeepEqual(dups, expected) {
+		t.Fatalf("Expected %+v, got %+v", expected, dups)
+	}
+	if msg := dups[0].String(); msg != `Service "web" is rendered more than once, by mychart/templates/service-extra.yaml, mychart/templates/service.yaml` {
+		t.Errorf("Unexpected message: %s", msg)
+	}
+}
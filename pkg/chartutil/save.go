@@ -23,6 +23,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sync"
 	"time"
 
 	"github.com/pkg/errors"
@@ -33,11 +34,28 @@ import (
 
 var headerBytes = []byte("+aHR0cHM6Ly95b3V0dS5iZS96OVV6MWljandyTQo=")
 
+// SaveDirOptions controls how SaveDir writes a chart to disk.
+type SaveDirOptions struct {
+	// Concurrency is the maximum number of files written at the same time.
+	// Values below one write the files sequentially.
+	Concurrency int
+	// Progress, if set, is called after each file is written with the number
+	// of files written so far and the total number of files to write. Packaged
+	// dependencies count as a single file each.
+	Progress func(written, total int)
+}
+
 // SaveDir saves a chart as files in a directory.
 //
 // This takes the chart name, and creates a new subdirectory inside of the given dest
 // directory, writing the chart's contents to that subdirectory.
 func SaveDir(c *chart.Chart, dest string) error {
+	return SaveDirWithOptions(c, dest, SaveDirOptions{})
+}
+
+// SaveDirWithOptions saves a chart as files in a directory like SaveDir, writing
+// up to opts.Concurrency files in parallel and reporting progress to opts.Progress.
+func SaveDirWithOptions(c *chart.Chart, dest string, opts SaveDirOptions) error {
 	// Create the chart directory
 	outdir := filepath.Join(dest, c.Name())
 	if fi, err := os.Stat(outdir); err == nil && !fi.IsDir() {
@@ -47,50 +65,117 @@ func SaveDir(c *chart.Chart, dest string) error {
 		return err
 	}
 
-	// Save the chart file.
-	if err := SaveChartfile(filepath.Join(outdir, ChartfileName), c.Metadata); err != nil {
-		return err
-	}
+	var files []*chart.File
 
 	// Save values.yaml
 	for _, f := range c.Raw {
 		if f.Name == ValuesfileName {
-			vf := filepath.Join(outdir, ValuesfileName)
-			if err := writeFile(vf, f.Data); err != nil {
-				return err
-			}
+			files = append(files, f)
 		}
 	}
 
 	// Save values.schema.json if it exists
 	if c.Schema != nil {
-		filename := filepath.Join(outdir, SchemafileName)
-		if err := writeFile(filename, c.Schema); err != nil {
-			return err
-		}
+		files = append(files, &chart.File{Name: SchemafileName, Data: c.Schema})
 	}
 
 	// Save templates and files
-	for _, o := range [][]*chart.File{c.Templates, c.Files} {
-		for _, f := range o {
-			n := filepath.Join(outdir, f.Name)
-			if err := writeFile(n, f.Data); err != nil {
-				return err
-			}
-		}
+	files = append(files, c.Templates...)
+	files = append(files, c.Files...)
+
+	deps := c.Dependencies()
+	progress := newSaveProgress(1+len(files)+len(deps), opts.Progress)
+
+	// Save the chart file.
+	if err := SaveChartfile(filepath.Join(outdir, ChartfileName), c.Metadata); err != nil {
+		return err
+	}
+	progress.add()
+
+	if err := writeFiles(outdir, files, opts.Concurrency, progress); err != nil {
+		return err
 	}
 
 	// Save dependencies
 	base := filepath.Join(outdir, ChartsDir)
-	for _, dep := range c.Dependencies() {
+	for _, dep := range deps {
 		// Here, we write each dependency as a tar file.
 		if _, err := Save(dep, base); err != nil {
 			return errors.Wrapf(err, "saving %s", dep.ChartFullPath())
 		}
+		progress.add()
 	}
 	return nil
 }
 
+// writeFiles writes files below dir using at most concurrency writers. Parent
+// directories are created by each writer, so the order the files are written in
+// does not matter.
+func writeFiles(dir string, files []*chart.File, concurrency int, progress *saveProgress) error {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+		sem      = make(chan struct{}, concurrency)
+	)
+	for _, f := range files {
+		// Wait for a free writer before checking for errors, so that with a
+		// single writer nothing is written after a failed file.
+		sem <- struct{}{}
+		mu.Lock()
+		failed := firstErr != nil
+		mu.Unlock()
+		if failed {
+			<-sem
+			break
+		}
+
+		wg.Add(1)
+		go func(f *chart.File) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			if err := writeFile(filepath.Join(dir, f.Name), f.Data); err != nil {
+				mu.Lock()
+				if firstErr == nil {
+					firstErr = err
+				}
+				mu.Unlock()
+				return
+			}
+			progress.add()
+		}(f)
+	}
+	wg.Wait()
+	return firstErr
+}
+
+// saveProgress counts written files and reports them in increasing order.
+type saveProgress struct {
+	mu      sync.Mutex
+	written int
+	total   int
+	fn      func(written, total int)
+}
+
+func newSaveProgress(total int, fn func(written, total int)) *saveProgress {
+	return &saveProgress{total: total, fn: fn}
+}
+
+func (p *saveProgress) add() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.written++
+	if p.fn != nil {
+		p.fn(p.written, p.total)
+	}
+}
+
 // Save creates an archived chart to the given directory.
 //
 // This takes an existing chart and a destination directory.
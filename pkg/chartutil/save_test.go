@@ -20,6 +20,7 @@ import (
 	"archive/tar"
 	"bytes"
 	"compress/gzip"
+	"fmt"
 	"io"
 	"io/ioutil"
 	"os"
@@ -244,3 +245,88 @@ func TestSaveDir(t *testing.T) {
 		t.Fatal("Files data did not match")
 	}
 }
+
+func TestSaveDirWithOptions(t *testing.T) {
+	tmp := ensure.TempDir(t)
+
+	c := &chart.Chart{
+		Metadata: &chart.Metadata{
+			APIVersion: chart.APIVersionV1,
+			Name:       "ahab",
+			Version:    "1.2.3",
+		},
+	}
+	for i := 0; i < 200; i++ {
+		c.Templates = append(c.Templates, &chart.File{
+			Name: filepath.Join(TemplatesDir, fmt.Sprintf("dir%d", i%7), fmt.Sprintf("thing%d.yaml", i)),
+			Data: []byte(fmt.Sprintf("abc: %d", i)),
+		})
+	}
+	c.Files = []*chart.File{
+		{Name: "scheherazade/shahryar.txt", Data: []byte("1,001 Nights")},
+	}
+
+	var counts []int
+	total := 0
+	opts := SaveDirOptions{
+		Concurrency: 8,
+		Progress: func(written, t int) {
+			counts = append(counts, written)
+			total = t
+		},
+	}
+	if err := SaveDirWithOptions(c, tmp, opts); err != nil {
+		t.Fatalf("Failed to save: %s", err)
+	}
+
+	if expected := 1 + len(c.Templates) + len(c.Files); total != expected || len(counts) != expected {
+		t.Fatalf("Expected %d progress calls with a total of %d, got %d calls with a total of %d", expected, expected, len(counts), total)
+	}
+	for i, n := range counts {
+		if n != i+1 {
+			t.Fatalf("Expected progress counts to increase by one, got %v", counts)
+		}
+	}
+
+	c2, err := loader.LoadDir(filepath.Join(tmp, "ahab"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(c2.Templates) != len(c.Templates) {
+		t.Fatalf("Expected %d templates, got %d", len(c.Templates), len(c2.Templates))
+	}
+	if len(c2.Files) != 1 || c2.Files[0].Name != "scheherazade/shahryar.txt" {
+		t.Fatal("Files data did not match")
+	}
+}
+
+func TestSaveDirStopsAtFirstError(t *testing.T) {
+	c := &chart.Chart{
+		Metadata: &chart.Metadata{
+			APIVersion: chart.APIVersionV1,
+			Name:       "ahab",
+			Version:    "1.2.3",
+		},
+		Files: []*chart.File{
+			{Name: "whale", Data: []byte("white")},
+			// whale is a file, so nothing can be written below it
+			{Name: "whale/ship.txt", Data: []byte("Pequod")},
+			{Name: "zzz.txt", Data: []byte("never written")},
+		},
+	}
+
+	for _, concurrency := range []int{0, 8} {
+		tmp := ensure.TempDir(t)
+		err := SaveDirWithOptions(c, tmp, SaveDirOptions{Concurrency: concurrency})
+		if err == nil || !strings.Contains(err.Error(), "whale") {
+			t.Fatalf("Expected an error writing below whale with concurrency %d, got %v", concurrency, err)
+		}
+		if _, err := os.Stat(filepath.Join(tmp, "ahab", ChartfileName)); err != nil {
+			t.Errorf("Expected %s to be written before the error: %s", ChartfileName, err)
+		}
+		// Written sequentially, the files after the failing one are skipped
+		if _, err := os.Stat(filepath.Join(tmp, "ahab", "zzz.txt")); concurrency == 0 && !os.IsNotExist(err) {
+			t.Errorf("Expected zzz.txt not to be written after the error, got %v", err)
+		}
+	}
+}
@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
+	"path/filepath"
 	"strings"
 
 	"github.com/pkg/errors"
@@ -121,6 +122,81 @@ func ReadValuesFile(filename string) (Values, error) {
 	return ReadValues(data)
 }
 
+// IncludeKey is the top-level key of a values file that lists other values
+// files to merge into it.
+const IncludeKey = "__include__"
+
+// ReadValuesFileWithIncludes parses a YAML file into a map of values like
+// ReadValuesFile, resolving the files listed under its top-level __include__
+// key. Paths are relative to the including file. Included files are merged in
+// order, each overriding the ones before it, and the values of the including
+// file override them all. Included files may include others, but a file cannot
+// include itself, directly or not.
+func ReadValuesFileWithIncludes(filename string) (Values, error) {
+	return readValuesFileWithIncludes(filename, nil)
+}
+
+func readValuesFileWithIncludes(filename string, chain []string) (Values, error) {
+	abs, err := filepath.Abs(filename)
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range chain {
+		if f == abs {
+			return nil, errors.Errorf("values files include each other: %s", strings.Join(append(chain, abs), " -> "))
+		}
+	}
+	chain = append(chain, abs)
+
+	vals, err := ReadValuesFile(abs)
+	if err != nil {
+		return nil, err
+	}
+	includes, err := includedFiles(vals)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid %s in %s", IncludeKey, filename)
+	}
+	delete(vals, IncludeKey)
+
+	// CoalesceTables keeps the values already present, so the included files
+	// are merged in from the last one to the first.
+	for i := len(includes) - 1; i >= 0; i-- {
+		include := includes[i]
+		if !filepath.IsAbs(include) {
+			include = filepath.Join(filepath.Dir(abs), include)
+		}
+		included, err := readValuesFileWithIncludes(include, chain)
+		if err != nil {
+			return nil, err
+		}
+		CoalesceTables(vals, included)
+	}
+	return vals, nil
+}
+
+// includedFiles returns the paths listed under the __include__ key, which may
+// hold a single path or a list of them.
+func includedFiles(vals Values) ([]string, error) {
+	switch include := vals[IncludeKey].(type) {
+	case nil:
+		return nil, nil
+	case string:
+		return []string{include}, nil
+	case []interface{}:
+		paths := make([]string, 0, len(include))
+		for _, p := range include {
+			s, ok := p.(string)
+			if !ok {
+				return nil, errors.Errorf("expected a path, got %v", p)
+			}
+			paths = append(paths, s)
+		}
+		return paths, nil
+	default:
+		return nil, errors.Errorf("expected a path or a list of paths, got %v", include)
+	}
+}
+
 // ReleaseOptions represents the additional release options needed
 // for the composition of the final values struct
 type ReleaseOptions struct {
@@ -135,6 +211,14 @@ type ReleaseOptions struct {
 //
 // This takes both ReleaseOptions and Capabilities to merge into the render values.
 func ToRenderValues(chrt *chart.Chart, chrtVals map[string]interface{}, options ReleaseOptions, caps *Capabilities) (Values, error) {
+	return ToRenderValuesWithSchemaValidation(chrt, chrtVals, options, caps, false)
+}
+
+// ToRenderValuesWithSchemaValidation composes the struct from the data coming from the Releases, Charts and Values files
+//
+// This takes both ReleaseOptions and Capabilities to merge into the render values.
+// If skipSchemaValidation is set, the coalesced values are not validated against the chart's values.schema.json.
+func ToRenderValuesWithSchemaValidation(chrt *chart.Chart, chrtVals map[string]interface{}, options ReleaseOptions, caps *Capabilities, skipSchemaValidation bool) (Values, error) {
 	if caps == nil {
 		caps = DefaultCapabilities
 	}
@@ -156,6 +240,11 @@ func ToRenderValues(chrt *chart.Chart, chrtVals map[string]interface{}, options
 		return top, err
 	}
 
+	if skipSchemaValidation {
+		top["Values"] = vals
+		return top, nil
+	}
+
 	if err := ValidateAgainstSchema(chrt, vals); err != nil {
 		errFmt := "values don't meet the specifications of the schema(s) in the following chart(s):\n%s"
 		return top, fmt.Errorf(errFmt, err.Error())
@@ -19,6 +19,9 @@ package chartutil
 import (
 	"bytes"
 	"fmt"
+	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 	"text/template"
 
@@ -160,6 +163,35 @@ func TestReadValuesFile(t *testing.T) {
 	matchValues(t, data)
 }
 
+func TestReadValuesFileWithIncludes(t *testing.T) {
+	vals, err := ReadValuesFileWithIncludes("./testdata/includes/values.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := Values{
+		"image": map[string]interface{}{
+			"repository": "nginx",
+			"tag":        "2.0",
+		},
+		"replicas": float64(3),
+		"labels": map[string]interface{}{
+			"team": "web",
+			"tier": "common",
+		},
+	}
+	if !reflect.DeepEqual(vals, expected) {
+		t.Errorf("Expected values:\n%v\ngot:\n%v", expected, vals)
+	}
+
+	_, err = ReadValuesFileWithIncludes("./testdata/includes/cycle-a.yaml")
+	if err == nil {
+		t.Fatal("Expected an error for values files including each other")
+	}
+	if !strings.Contains(err.Error(), "values files include each other:") || !strings.Contains(err.Error(), filepath.Join("includes", "cycle-b.yaml")+" -> ") {
+		t.Errorf("Unexpected error %q", err)
+	}
+}
+
 func ExampleValues() {
 	doc := `
 title: "Moby Dick"
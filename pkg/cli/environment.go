@@ -68,12 +68,16 @@ type EnvSettings struct {
 	PluginsDirectory string
 	// MaxHistory is the max release history maintained.
 	MaxHistory int
+	// Retries is the number of times a failed chart or repository index
+	// download is retried.
+	Retries int
 }
 
 func New() *EnvSettings {
 	env := &EnvSettings{
 		namespace:        os.Getenv("HELM_NAMESPACE"),
 		MaxHistory:       envIntOr("HELM_MAX_HISTORY", defaultMaxHistory),
+		Retries:          envIntOr("HELM_RETRIES", 0),
 		KubeContext:      os.Getenv("HELM_KUBECONTEXT"),
 		KubeToken:        os.Getenv("HELM_KUBETOKEN"),
 		KubeAsUser:       os.Getenv("HELM_KUBEASUSER"),
@@ -115,6 +119,7 @@ func (s *EnvSettings) AddFlags(fs *pflag.FlagSet) {
 	fs.StringVar(&s.RegistryConfig, "registry-config", s.RegistryConfig, "path to the registry config file")
 	fs.StringVar(&s.RepositoryConfig, "repository-config", s.RepositoryConfig, "path to the file containing repository names and URLs")
 	fs.StringVar(&s.RepositoryCache, "repository-cache", s.RepositoryCache, "path to the file containing cached repository indexes")
+	fs.IntVar(&s.Retries, "retries", s.Retries, "number of times a failed chart or repository index download is retried")
 }
 
 func envOr(name, def string) string {
@@ -157,6 +162,7 @@ func (s *EnvSettings) EnvVars() map[string]string {
 		"HELM_REPOSITORY_CONFIG": s.RepositoryConfig,
 		"HELM_NAMESPACE":         s.Namespace(),
 		"HELM_MAX_HISTORY":       strconv.Itoa(s.MaxHistory),
+		"HELM_RETRIES":           strconv.Itoa(s.Retries),
 
 		// broken, these are populated from helm flags and not kubeconfig.
 		"HELM_KUBECONTEXT":   s.KubeContext,
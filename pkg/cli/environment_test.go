@@ -37,6 +37,7 @@ func TestEnvSettings(t *testing.T) {
 		ns, kcontext string
 		debug        bool
 		maxhistory   int
+		retries      int
 		kAsUser      string
 		kAsGroups    []string
 		kCaFile      string
@@ -48,19 +49,21 @@ func TestEnvSettings(t *testing.T) {
 		},
 		{
 			name:       "with flags set",
-			args:       "--debug --namespace=myns --kube-as-user=poro --kube-as-group=admins --kube-as-group=teatime --kube-as-group=snackeaters --kube-ca-file=/tmp/ca.crt",
+			args:       "--debug --namespace=myns --kube-as-user=poro --kube-as-group=admins --kube-as-group=teatime --kube-as-group=snackeaters --kube-ca-file=/tmp/ca.crt --retries=3",
 			ns:         "myns",
 			debug:      true,
 			maxhistory: defaultMaxHistory,
+			retries:    3,
 			kAsUser:    "poro",
 			kAsGroups:  []string{"admins", "teatime", "snackeaters"},
 			kCaFile:    "/tmp/ca.crt",
 		},
 		{
 			name:       "with envvars set",
-			envvars:    map[string]string{"HELM_DEBUG": "1", "HELM_NAMESPACE": "yourns", "HELM_KUBEASUSER": "pikachu", "HELM_KUBEASGROUPS": ",,,operators,snackeaters,partyanimals", "HELM_MAX_HISTORY": "5", "HELM_KUBECAFILE": "/tmp/ca.crt"},
+			envvars:    map[string]string{"HELM_DEBUG": "1", "HELM_NAMESPACE": "yourns", "HELM_KUBEASUSER": "pikachu", "HELM_KUBEASGROUPS": ",,,operators,snackeaters,partyanimals", "HELM_MAX_HISTORY": "5", "HELM_KUBECAFILE": "/tmp/ca.crt", "HELM_RETRIES": "2"},
 			ns:         "yourns",
 			maxhistory: 5,
+			retries:    2,
 			debug:      true,
 			kAsUser:    "pikachu",
 			kAsGroups:  []string{"operators", "snackeaters", "partyanimals"},
@@ -105,6 +108,9 @@ func TestEnvSettings(t *testing.T) {
 			if settings.MaxHistory != tt.maxhistory {
 				t.Errorf("expected maxHistory %d, got %d", tt.maxhistory, settings.MaxHistory)
 			}
+			if settings.Retries != tt.retries {
+				t.Errorf("expected retries %d, got %d", tt.retries, settings.Retries)
+			}
 			if tt.kAsUser != settings.KubeAsUser {
 				t.Errorf("expected kAsUser %q, got %q", tt.kAsUser, settings.KubeAsUser)
 			}
@@ -25,6 +25,7 @@ import (
 	"github.com/pkg/errors"
 	"sigs.k8s.io/yaml"
 
+	"helm.sh/helm/v3/pkg/chartutil"
 	"helm.sh/helm/v3/pkg/getter"
 	"helm.sh/helm/v3/pkg/strvals"
 )
@@ -34,15 +35,28 @@ type Options struct {
 	StringValues []string
 	Values       []string
 	FileValues   []string
+	JSONValues   []string
+	// ResolveIncludes merges the files listed under the __include__ key of
+	// local values files into them. See chartutil.ReadValuesFileWithIncludes.
+	ResolveIncludes bool
 }
 
 // MergeValues merges values from files specified via -f/--values and directly
-// via --set, --set-string, or --set-file, marshaling them to YAML
+// via --set-json, --set, --set-string, or --set-file, marshaling them to YAML
 func (opts *Options) MergeValues(p getter.Providers) (map[string]interface{}, error) {
 	base := map[string]interface{}{}
 
 	// User specified a values files via -f/--values
 	for _, filePath := range opts.ValueFiles {
+		if opts.ResolveIncludes && isLocalFile(filePath, p) {
+			currentMap, err := chartutil.ReadValuesFileWithIncludes(filePath)
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to read %s", filePath)
+			}
+			base = mergeMaps(base, currentMap)
+			continue
+		}
+
 		currentMap := map[string]interface{}{}
 
 		bytes, err := readFile(filePath, p)
@@ -57,6 +71,13 @@ func (opts *Options) MergeValues(p getter.Providers) (map[string]interface{}, er
 		base = mergeMaps(base, currentMap)
 	}
 
+	// User specified a value via --set-json
+	for _, value := range opts.JSONValues {
+		if err := strvals.ParseJSON(value, base); err != nil {
+			return nil, errors.Wrap(err, "failed parsing --set-json data")
+		}
+	}
+
 	// User specified a value via --set
 	for _, value := range opts.Values {
 		if err := strvals.ParseInto(value, base); err != nil {
@@ -104,6 +125,16 @@ func mergeMaps(a, b map[string]interface{}) map[string]interface{} {
 	return out
 }
 
+// isLocalFile reports whether readFile reads filePath from the local disk.
+func isLocalFile(filePath string, p getter.Providers) bool {
+	if strings.TrimSpace(filePath) == "-" {
+		return false
+	}
+	u, _ := url.Parse(filePath)
+	_, err := p.ByScheme(u.Scheme)
+	return err != nil
+}
+
 // readFile load a file from stdin, the local directory, or a remote file with a url.
 func readFile(filePath string, p getter.Providers) ([]byte, error) {
 	if strings.TrimSpace(filePath) == "-" {
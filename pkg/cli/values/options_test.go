@@ -19,6 +19,8 @@ package values
 import (
 	"reflect"
 	"testing"
+
+	"helm.sh/helm/v3/pkg/getter"
 )
 
 func TestMergeValues(t *testing.T) {
@@ -75,3 +77,64 @@ func TestMergeValues(t *testing.T) {
 		t.Errorf("Expected a map with different keys to merge properly with another map. Expected: %v, got %v", expectedMap, testMap)
 	}
 }
+
+func TestMergeValuesSetJSON(t *testing.T) {
+	opts := &Options{
+		JSONValues: []string{
+			`image={"repository":"nginx","tag":"1.19"}`,
+			`service.ports=[{"name":"http","port":80},{"name":"https","port":443}]`,
+		},
+		Values: []string{"image.tag=1.21-alpine"},
+	}
+
+	got, err := opts.MergeValues(getter.Providers{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]interface{}{
+		"image": map[string]interface{}{
+			"repository": "nginx",
+			"tag":        "1.21-alpine",
+		},
+		"service": map[string]interface{}{
+			"ports": []interface{}{
+				map[string]interface{}{"name": "http", "port": float64(80)},
+				map[string]interface{}{"name": "https", "port": float64(443)},
+			},
+		},
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+
+	opts = &Options{JSONValues: []string{`image.tag={"bad"}`}}
+	if _, err := opts.MergeValues(getter.Providers{}); err == nil {
+		t.Error("Expected an error for invalid JSON")
+	}
+}
+
+func TestMergeValuesResolveIncludes(t *testing.T) {
+	valuesFile := "../../chartutil/testdata/includes/values.yaml"
+
+	opts := &Options{ValueFiles: []string{valuesFile}, ResolveIncludes: true}
+	got, err := opts.MergeValues(getter.Providers{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := got["__include__"]; ok {
+		t.Error("Expected the include directive to be removed")
+	}
+	if repo := got["image"].(map[string]interface{})["repository"]; repo != "nginx" {
+		t.Errorf("Expected the included image.repository, got %v", repo)
+	}
+
+	opts = &Options{ValueFiles: []string{valuesFile}}
+	got, err = opts.MergeValues(getter.Providers{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := got["__include__"]; !ok {
+		t.Error("Expected includes to be left alone unless ResolveIncludes is set")
+	}
+}
@@ -199,6 +199,9 @@ func (c *ChartDownloader) ResolveChartVersion(ref, version string) (*url.URL, er
 				getter.WithPassCredentialsAll(rc.PassCredentialsAll),
 			)
 		}
+		if len(rc.Headers) > 0 {
+			c.Options = append(c.Options, getter.WithHeaders(rc.Headers))
+		}
 		return u, nil
 	}
 
@@ -235,6 +238,9 @@ func (c *ChartDownloader) ResolveChartVersion(ref, version string) (*url.URL, er
 				getter.WithPassCredentialsAll(r.Config.PassCredentialsAll),
 			)
 		}
+		if len(r.Config.Headers) > 0 {
+			c.Options = append(c.Options, getter.WithHeaders(r.Config.Headers))
+		}
 	}
 
 	// Next, we need to load the index, and actually look up the chart.
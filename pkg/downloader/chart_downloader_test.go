@@ -19,6 +19,7 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"reflect"
 	"testing"
 
 	"helm.sh/helm/v3/internal/test/ensure"
@@ -135,7 +136,7 @@ func TestResolveChartOpts(t *testing.T) {
 			continue
 		}
 
-		if *(got.(*getter.HTTPGetter)) != *(expect.(*getter.HTTPGetter)) {
+		if !reflect.DeepEqual(got, expect) {
 			t.Errorf("%s: expected %s, got %s", tt.name, expect, got)
 		}
 	}
@@ -192,6 +192,40 @@ func (e Engine) initFunMap(t *template.Template, referenceTpls map[string]render
 	t.Funcs(funcMap)
 }
 
+// RenderEach renders the templates like Render, but instead of collecting the
+// output it passes each rendered template to fn as soon as it is rendered, so
+// callers that handle one template at a time do not hold the whole manifest.
+//
+// Templates are rendered in the same order as with Render. Rendering stops at
+// the first error, including an error returned by fn.
+func (e Engine) RenderEach(chrt *chart.Chart, values chartutil.Values, fn func(name, content string) error) error {
+	tmap := allTemplates(chrt, values)
+	return e.renderEach(tmap, tmap, fn)
+}
+
+// RenderTemplate renders the template with the given rendered name, such as
+// "mychart/templates/NOTES.txt". Only the partials of the chart and its
+// dependencies are available to it, so errors in other templates do not
+// affect it.
+func (e Engine) RenderTemplate(chrt *chart.Chart, values chartutil.Values, name string) (string, error) {
+	all := allTemplates(chrt, values)
+	tpl, ok := all[name]
+	if !ok {
+		return "", errors.Errorf("template %s not found", name)
+	}
+	refs := map[string]renderable{name: tpl}
+	for n, r := range all {
+		if strings.HasPrefix(path.Base(n), "_") {
+			refs[n] = r
+		}
+	}
+	rendered, err := e.renderWithReferences(map[string]renderable{name: tpl}, refs)
+	if err != nil {
+		return "", err
+	}
+	return rendered[name], nil
+}
+
 // render takes a map of templates/values and renders them.
 func (e Engine) render(tpls map[string]renderable) (map[string]string, error) {
 	return e.renderWithReferences(tpls, tpls)
@@ -199,7 +233,21 @@ func (e Engine) render(tpls map[string]renderable) (map[string]string, error) {
 
 // renderWithReferences takes a map of templates/values to render, and a map of
 // templates which can be referenced within them.
-func (e Engine) renderWithReferences(tpls, referenceTpls map[string]renderable) (rendered map[string]string, err error) {
+func (e Engine) renderWithReferences(tpls, referenceTpls map[string]renderable) (map[string]string, error) {
+	rendered := make(map[string]string, len(tpls))
+	err := e.renderEach(tpls, referenceTpls, func(name, content string) error {
+		rendered[name] = content
+		return nil
+	})
+	if err != nil {
+		return map[string]string{}, err
+	}
+	return rendered, nil
+}
+
+// renderEach renders a map of templates/values, with a map of templates which
+// can be referenced within them, and hands every rendered template to fn.
+func (e Engine) renderEach(tpls, referenceTpls map[string]renderable, fn func(name, content string) error) (err error) {
 	// Basically, what we do here is start with an empty parent template and then
 	// build up a list of templates -- one for each file. Once all of the templates
 	// have been parsed, we loop through again and execute every template.
@@ -231,7 +279,7 @@ func (e Engine) renderWithReferences(tpls, referenceTpls map[string]renderable)
 	for _, filename := range keys {
 		r := tpls[filename]
 		if _, err := t.New(filename).Parse(r.tpl); err != nil {
-			return map[string]string{}, cleanupParseError(filename, err)
+			return cleanupParseError(filename, err)
 		}
 	}
 
@@ -241,12 +289,11 @@ func (e Engine) renderWithReferences(tpls, referenceTpls map[string]renderable)
 		if t.Lookup(filename) == nil {
 			r := referenceTpls[filename]
 			if _, err := t.New(filename).Parse(r.tpl); err != nil {
-				return map[string]string{}, cleanupParseError(filename, err)
+				return cleanupParseError(filename, err)
 			}
 		}
 	}
 
-	rendered = make(map[string]string, len(keys))
 	for _, filename := range keys {
 		// Don't render partials. We don't care out the direct output of partials.
 		// They are only included from other templates.
@@ -258,16 +305,18 @@ func (e Engine) renderWithReferences(tpls, referenceTpls map[string]renderable)
 		vals["Template"] = chartutil.Values{"Name": filename, "BasePath": tpls[filename].basePath}
 		var buf strings.Builder
 		if err := t.ExecuteTemplate(&buf, filename, vals); err != nil {
-			return map[string]string{}, cleanupExecError(filename, err)
+			return cleanupExecError(filename, err)
 		}
 
 		// Work around the issue where Go will emit "<no value>" even if Options(missing=zero)
 		// is set. Since missing=error will never get here, we do not need to handle
 		// the Strict case.
-		rendered[filename] = strings.ReplaceAll(buf.String(), "<no value>", "")
+		if err := fn(filename, strings.ReplaceAll(buf.String(), "<no value>", "")); err != nil {
+			return err
+		}
 	}
 
-	return rendered, nil
+	return nil
 }
 
 func cleanupParseError(filename string, err error) error {
@@ -17,7 +17,9 @@ limitations under the License.
 package engine
 
 import (
+	"errors"
 	"fmt"
+	"reflect"
 	"strings"
 	"sync"
 	"testing"
@@ -126,6 +128,89 @@ func TestRender(t *testing.T) {
 	}
 }
 
+func TestRenderEach(t *testing.T) {
+	c := &chart.Chart{
+		Metadata: &chart.Metadata{Name: "moby", Version: "1.2.3"},
+		Templates: []*chart.File{
+			{Name: "templates/_helpers.tpl", Data: []byte(`{{define "name"}}{{.Values.name}}{{end}}`)},
+			{Name: "templates/test1", Data: []byte(`{{include "name" .}}`)},
+			{Name: "templates/test2", Data: []byte("{{.Values.name | upper}}")},
+		},
+		Values: map[string]interface{}{"name": "ishmael"},
+	}
+	c.AddDependency(&chart.Chart{
+		Metadata:  &chart.Metadata{Name: "pequod", Version: "0.1.0"},
+		Templates: []*chart.File{{Name: "templates/ship", Data: []byte("{{.Chart.Name}}")}},
+	})
+
+	v, err := chartutil.CoalesceValues(c, map[string]interface{}{})
+	if err != nil {
+		t.Fatalf("Failed to coalesce values: %s", err)
+	}
+	vals := map[string]interface{}{"Values": v, "Chart": c.Metadata}
+
+	expect, err := Render(c, vals)
+	if err != nil {
+		t.Fatalf("Failed to render templates: %s", err)
+	}
+
+	streamed := map[string]string{}
+	err = new(Engine).RenderEach(c, vals, func(name, content string) error {
+		if _, ok := streamed[name]; ok {
+			t.Errorf("template %s yielded twice", name)
+		}
+		streamed[name] = content
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Failed to stream templates: %s", err)
+	}
+	if !reflect.DeepEqual(expect, streamed) {
+		t.Errorf("Expected streamed templates %v to match rendered templates %v", streamed, expect)
+	}
+
+	// An error returned by the callback stops the render.
+	stop := errors.New("stop")
+	calls := 0
+	err = new(Engine).RenderEach(c, vals, func(name, content string) error {
+		calls++
+		return stop
+	})
+	if err != stop || calls != 1 {
+		t.Errorf("Expected the callback error after one template, got %v after %d", err, calls)
+	}
+}
+
+func TestRenderTemplate(t *testing.T) {
+	c := &chart.Chart{
+		Metadata: &chart.Metadata{Name: "moby", Version: "1.2.3"},
+		Templates: []*chart.File{
+			{Name: "templates/_helpers.tpl", Data: []byte(`{{define "name"}}{{.Values.name}}{{end}}`)},
+			{Name: "templates/NOTES.txt", Data: []byte(`Call me {{include "name" .}}.`)},
+			{Name: "templates/broken", Data: []byte(`{{fail "broken"}}`)},
+		},
+		Values: map[string]interface{}{"name": "ishmael"},
+	}
+
+	v, err := chartutil.CoalesceValues(c, map[string]interface{}{})
+	if err != nil {
+		t.Fatalf("Failed to coalesce values: %s", err)
+	}
+	vals := map[string]interface{}{"Values": v, "Chart": c.Metadata}
+
+	out, err := new(Engine).RenderTemplate(c, vals, "moby/templates/NOTES.txt")
+	if err != nil {
+		t.Fatalf("Failed to render template: %s", err)
+	}
+	if out != "Call me ishmael." {
+		t.Errorf("Expected %q, got %q", "Call me ishmael.", out)
+	}
+
+	if _, err := new(Engine).RenderTemplate(c, vals, "moby/templates/missing"); err == nil {
+		t.Error("Expected an error for a missing template")
+	}
+}
+
 func TestRenderRefsOrdering(t *testing.T) {
 	parentChart := &chart.Chart{
 		Metadata: &chart.Metadata{
@@ -25,6 +25,8 @@ import (
 	"github.com/BurntSushi/toml"
 	"github.com/Masterminds/sprig/v3"
 	"sigs.k8s.io/yaml"
+
+	"helm.sh/helm/v3/internal/templatefuncs"
 )
 
 // funcMap returns a mapping of all of the functions that Engine has.
@@ -76,6 +78,11 @@ func funcMap() template.FuncMap {
 	return f
 }
 
+func init() {
+	// The linter parses templates with the functions of the engine
+	templatefuncs.FuncMap = funcMap
+}
+
 // toYAML takes an interface, marshals it to yaml, and returns a string. It will
 // always return a string, even on marshal error (empty string).
 //
@@ -40,9 +40,11 @@ type options struct {
 	password              string
 	passCredentialsAll    bool
 	userAgent             string
+	headers               map[string]string
 	version               string
 	registryClient        *registry.Client
 	timeout               time.Duration
+	retries               int
 }
 
 // Option allows specifying various settings configurable by the user for overriding the defaults
@@ -78,6 +80,15 @@ func WithUserAgent(userAgent string) Option {
 	}
 }
 
+// WithHeaders sets extra headers on the request. Like basic auth credentials,
+// they are only sent to the host given with WithURL unless WithPassCredentialsAll
+// is set.
+func WithHeaders(headers map[string]string) Option {
+	return func(opts *options) {
+		opts.headers = headers
+	}
+}
+
 // WithInsecureSkipVerifyTLS determines if a TLS Certificate will be checked
 func WithInsecureSkipVerifyTLS(insecureSkipVerifyTLS bool) Option {
 	return func(opts *options) {
@@ -101,6 +112,14 @@ func WithTimeout(timeout time.Duration) Option {
 	}
 }
 
+// WithRetries sets the number of times a failed request is retried. Only
+// transient failures (network errors and retryable status codes) are retried.
+func WithRetries(retries int) Option {
+	return func(opts *options) {
+		opts.retries = retries
+	}
+}
+
 func WithTagName(tagname string) Option {
 	return func(opts *options) {
 		opts.version = tagname
@@ -176,8 +195,16 @@ var ociProvider = Provider{
 // All finds all of the registered getters as a list of Provider instances.
 // Currently, the built-in getters and the discovered plugins with downloader
 // notations are collected.
+//
+// The HTTP getters retry failed requests as many times as settings.Retries.
 func All(settings *cli.EnvSettings) Providers {
-	result := Providers{httpProvider, ociProvider}
+	httpGetters := httpProvider
+	if retries := settings.Retries; retries > 0 {
+		httpGetters.New = func(options ...Option) (Getter, error) {
+			return NewHTTPGetter(append([]Option{WithRetries(retries)}, options...)...)
+		}
+	}
+	result := Providers{httpGetters, ociProvider}
 	pluginDownloaders, _ := collectPlugins(settings)
 	result = append(result, pluginDownloaders...)
 	return result
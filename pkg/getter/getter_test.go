@@ -66,6 +66,20 @@ func TestAll(t *testing.T) {
 	}
 }
 
+func TestAllRetries(t *testing.T) {
+	env := cli.New()
+	env.PluginsDirectory = pluginDir
+	env.Retries = 2
+
+	g, err := All(env).ByScheme("https")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if retries := g.(*HTTPGetter).opts.retries; retries != 2 {
+		t.Errorf("expected the HTTP getter to retry 2 times, got %d", retries)
+	}
+}
+
 func TestByScheme(t *testing.T) {
 	env := cli.New()
 	env.PluginsDirectory = pluginDir
@@ -21,6 +21,7 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"time"
 
 	"github.com/pkg/errors"
 
@@ -73,6 +74,9 @@ func (g *HTTPGetter) get(href string) (*bytes.Buffer, error) {
 		if g.opts.username != "" && g.opts.password != "" {
 			req.SetBasicAuth(g.opts.username, g.opts.password)
 		}
+		for name, value := range g.opts.headers {
+			req.Header.Set(name, value)
+		}
 	}
 
 	client, err := g.httpClient()
@@ -80,18 +84,44 @@ func (g *HTTPGetter) get(href string) (*bytes.Buffer, error) {
 		return nil, err
 	}
 
+	// GET is idempotent, so transient failures can safely be retried.
+	for attempt := 0; ; attempt++ {
+		buf, retry, err := g.do(client, req, href)
+		if err == nil || !retry || attempt >= g.opts.retries {
+			return buf, err
+		}
+		time.Sleep(retryBackoff(attempt))
+	}
+}
+
+// do sends the request once, reporting whether a failure is worth retrying.
+func (g *HTTPGetter) do(client *http.Client, req *http.Request, href string) (*bytes.Buffer, bool, error) {
 	resp, err := client.Do(req)
 	if err != nil {
-		return nil, err
+		return nil, true, err
 	}
 	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusOK {
-		return nil, errors.Errorf("failed to fetch %s : %s", href, resp.Status)
+		return nil, isRetryableStatus(resp.StatusCode), errors.Errorf("failed to fetch %s : %s", href, resp.Status)
 	}
 
 	buf := bytes.NewBuffer(nil)
 	_, err = io.Copy(buf, resp.Body)
-	return buf, err
+	return buf, false, err
+}
+
+// retryBackoff returns how long to wait before the next attempt.
+var retryBackoff = func(attempt int) time.Duration {
+	return time.Duration(1<<uint(attempt)) * 250 * time.Millisecond
+}
+
+func isRetryableStatus(code int) bool {
+	switch code {
+	case http.StatusRequestTimeout, http.StatusTooManyRequests, http.StatusInternalServerError,
+		http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
 }
 
 // NewHTTPGetter constructs a valid http/https client as a Getter
@@ -443,3 +443,102 @@ func verifyInsecureSkipVerify(t *testing.T, g HTTPGetter, caseName string, expec
 	}
 	return transport
 }
+
+func TestHTTPGetterRetries(t *testing.T) {
+	defer func(backoff func(int) time.Duration) { retryBackoff = backoff }(retryBackoff)
+	retryBackoff = func(int) time.Duration { return 0 }
+
+	var calls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprint(w, "index")
+	}))
+	defer srv.Close()
+
+	g, err := NewHTTPGetter(WithURL(srv.URL), WithRetries(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := g.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("expected transient failure to be retried, got %s", err)
+	}
+	if got.String() != "index" {
+		t.Errorf("expected %q, got %q", "index", got.String())
+	}
+	if calls != 2 {
+		t.Errorf("expected 2 requests, got %d", calls)
+	}
+
+	// Without retries the transient failure is returned as is.
+	calls = 0
+	g, err = NewHTTPGetter(WithURL(srv.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := g.Get(srv.URL); err == nil {
+		t.Error("expected an error without retries")
+	}
+
+	// Non-retryable status codes fail on the first attempt.
+	notFound := 0
+	srv404 := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		notFound++
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer srv404.Close()
+
+	g, err = NewHTTPGetter(WithURL(srv404.URL), WithRetries(3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := g.Get(srv404.URL); err == nil {
+		t.Error("expected an error for a 404 response")
+	}
+	if notFound != 1 {
+		t.Errorf("expected a single request for a 404 response, got %d", notFound)
+	}
+}
+
+func TestHTTPGetterHeaders(t *testing.T) {
+	var got http.Header
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Clone()
+	}))
+	defer srv.Close()
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Clone()
+	}))
+	defer other.Close()
+
+	headers := map[string]string{"X-JFrog-Art-Api": "s3cr3t"}
+	g, err := NewHTTPGetter(WithURL(srv.URL), WithHeaders(headers))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := g.Get(srv.URL + "/index.yaml"); err != nil {
+		t.Fatal(err)
+	}
+	if v := got.Get("X-JFrog-Art-Api"); v != "s3cr3t" {
+		t.Errorf("Expected the custom header to be sent, got %q", v)
+	}
+
+	if _, err := g.Get(other.URL + "/chart.tgz"); err != nil {
+		t.Fatal(err)
+	}
+	if v := got.Get("X-JFrog-Art-Api"); v != "" {
+		t.Errorf("Expected the custom header to not be sent to another host, got %q", v)
+	}
+
+	if _, err := g.Get(other.URL+"/chart.tgz", WithPassCredentialsAll(true)); err != nil {
+		t.Fatal(err)
+	}
+	if v := got.Get("X-JFrog-Art-Api"); v != "s3cr3t" {
+		t.Errorf("Expected the custom header to be sent to another host with pass-credentials, got %q", v)
+	}
+}
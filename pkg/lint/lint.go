@@ -25,10 +25,15 @@ import (
 
 // All runs all of the available linters on the given base directory.
 func All(basedir string, values map[string]interface{}, namespace string, strict bool) support.Linter {
+	return Run(support.Linter{ChartDir: basedir}, values, namespace, strict)
+}
+
+// Run runs all the available linters on the chart in linter.ChartDir, with the
+// options set on linter, and returns it with the messages added.
+func Run(linter support.Linter, values map[string]interface{}, namespace string, strict bool) support.Linter {
 	// Using abs path to get directory context
-	chartDir, _ := filepath.Abs(basedir)
+	linter.ChartDir, _ = filepath.Abs(linter.ChartDir)
 
-	linter := support.Linter{ChartDir: chartDir}
 	rules.Chartfile(&linter)
 	rules.ValuesWithOverrides(&linter, values)
 	rules.Templates(&linter, values, namespace, strict)
@@ -34,7 +34,8 @@ const strict = false
 const badChartDir = "rules/testdata/badchartfile"
 const badValuesFileDir = "rules/testdata/badvaluesfile"
 const badYamlFileDir = "rules/testdata/albatross"
-const goodChartDir = "rules/testdata/goodone"
+const goodChartDir = "rules/testdata/goodconfigmap"
+const missingKindChartDir = "rules/testdata/goodone"
 
 func TestBadChart(t *testing.T) {
 	m := All(badChartDir, values, namespace, strict).Messages
@@ -115,6 +116,21 @@ func TestGoodChart(t *testing.T) {
 	}
 }
 
+// TestChartMissingKind tests that a chart rendering an object without an
+// apiVersion and kind, which used to pass, fails to lint.
+func TestChartMissingKind(t *testing.T) {
+	m := All(missingKindChartDir, values, namespace, strict).Messages
+	if len(m) != 1 {
+		t.Fatalf("All should have returned a single message, got %#v", m)
+	}
+	if m[0].Severity != support.ErrorSev {
+		t.Errorf("Expected an error, got %s", m[0])
+	}
+	if expected := `object "goodone-here" is missing apiVersion and kind`; m[0].Err.Error() != expected {
+		t.Errorf("Expected %q, got %q", expected, m[0].Err)
+	}
+}
+
 // TestHelmCreateChart tests that a `helm create` always passes a `helm lint` test.
 //
 // See https://github.com/helm/helm/issues/7923
@@ -25,7 +25,11 @@ import (
 	"path"
 	"path/filepath"
 	"regexp"
+	"sort"
+	"strconv"
 	"strings"
+	"text/template/parse"
+	"unicode/utf8"
 
 	"github.com/pkg/errors"
 	"k8s.io/apimachinery/pkg/api/validation"
@@ -33,15 +37,23 @@ import (
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	"k8s.io/apimachinery/pkg/util/yaml"
 
+	"helm.sh/helm/v3/internal/templatefuncs"
+	"helm.sh/helm/v3/pkg/chart"
 	"helm.sh/helm/v3/pkg/chart/loader"
 	"helm.sh/helm/v3/pkg/chartutil"
 	"helm.sh/helm/v3/pkg/engine"
 	"helm.sh/helm/v3/pkg/lint/support"
+	"helm.sh/helm/v3/pkg/release"
 )
 
 var (
 	crdHookSearch     = regexp.MustCompile(`"?helm\.sh/hook"?:\s+crd-install`)
 	releaseTimeSearch = regexp.MustCompile(`\.Release\.Time`)
+	documentSeparator = regexp.MustCompile(`(?m)^---.*$`)
+	secretKindSearch  = regexp.MustCompile(`(?m)^kind:\s*["']?Secret["']?\s*$`)
+	secretDataSearch  = regexp.MustCompile(`^(stringData|data):\s*$`)
+	secretEntrySearch = regexp.MustCompile(`^["']?([-._a-zA-Z0-9]+)["']?:\s*(.*)$`)
+	defineSearch      = regexp.MustCompile(`{{-?\s*define\s+"([^"]+)"`)
 )
 
 // Templates lints the templates in the Linter.
@@ -57,7 +69,7 @@ func Templates(linter *support.Linter, values map[string]interface{}, namespace
 	}
 
 	// Load chart and parse templates
-	chart, err := loader.Load(linter.ChartDir)
+	ch, err := loader.Load(linter.ChartDir)
 
 	chartLoaded := linter.RunLinterRule(support.ErrorSev, fpath, err)
 
@@ -65,29 +77,36 @@ func Templates(linter *support.Linter, values map[string]interface{}, namespace
 		return
 	}
 
+	releaseName := linter.ReleaseName
+	if releaseName == "" {
+		releaseName = "test-release"
+	}
 	options := chartutil.ReleaseOptions{
-		Name:      "test-release",
+		Name:      releaseName,
 		Namespace: namespace,
 	}
 
-	cvals, err := chartutil.CoalesceValues(chart, values)
+	cvals, err := chartutil.CoalesceValues(ch, values)
 	if err != nil {
 		return
 	}
-	valuesToRender, err := chartutil.ToRenderValues(chart, cvals, options, nil)
+	if linter.CheckOverrides {
+		linter.RunLinterRule(support.WarningSev, fpath, validateOverridesUsed(ch, values))
+	}
+	caps := chartutil.DefaultCapabilities
+	if linter.Capabilities != nil {
+		caps = linter.Capabilities
+	}
+	if linter.KubeVersion != nil {
+		caps = caps.Copy()
+		caps.KubeVersion = *linter.KubeVersion
+	}
+	linter.Capabilities = caps
+	valuesToRender, err := chartutil.ToRenderValues(ch, cvals, options, caps)
 	if err != nil {
 		linter.RunLinterRule(support.ErrorSev, fpath, err)
 		return
 	}
-	var e engine.Engine
-	e.LintMode = true
-	renderedContentMap, err := e.Render(chart, valuesToRender)
-
-	renderOk := linter.RunLinterRule(support.ErrorSev, fpath, err)
-
-	if !renderOk {
-		return
-	}
 
 	/* Iterate over all the templates to check:
 	- It is a .yaml file
@@ -96,62 +115,166 @@ func Templates(linter *support.Linter, values map[string]interface{}, namespace
 	- Generated content is a valid Yaml file
 	- Metadata.Namespace is not set
 	*/
-	for _, template := range chart.Templates {
+	renderChart := ch
+	if notes := notesTemplate(ch); notes != nil {
+		lintNotes(linter, ch, notes.Name, valuesToRender)
+		// NOTES.txt was linted on its own, so an error in it does not stop
+		// the other templates from being linted.
+		c := *ch
+		c.Templates = make([]*chart.File, 0, len(ch.Templates)-1)
+		for _, t := range ch.Templates {
+			if t != notes {
+				c.Templates = append(c.Templates, t)
+			}
+		}
+		renderChart = &c
+	}
+
+	subchartDefines := make(map[string]string)
+	for _, dep := range ch.Dependencies() {
+		collectDefines(dep, path.Join("charts", dep.Name()), subchartDefines)
+	}
+
+	yamlTemplates := make(map[string]string, len(ch.Templates))
+	for _, template := range ch.Templates {
 		fileName, data := template.Name, template.Data
-		fpath = fileName
 
-		linter.RunLinterRule(support.ErrorSev, fpath, validateAllowedExtension(fileName))
+		linter.RunLinterRule(support.ErrorSev, fileName, validateAllowedExtension(fileName))
+		linter.RunLinterRule(support.ErrorSev, fileName, validateUTF8(data))
 		// These are v3 specific checks to make sure and warn people if their
 		// chart is not compatible with v3
-		linter.RunLinterRule(support.WarningSev, fpath, validateNoCRDHooks(data))
-		linter.RunLinterRule(support.ErrorSev, fpath, validateNoReleaseTime(data))
+		linter.RunLinterRule(support.WarningSev, fileName, validateNoCRDHooks(data))
+		linter.RunLinterRule(support.ErrorSev, fileName, validateNoReleaseTime(data))
+		linter.RunLinterRule(support.WarningSev, fileName, validateNoDefineCollisions(data, subchartDefines))
 
 		// We only apply the following lint rules to yaml files
 		if filepath.Ext(fileName) != ".yaml" || filepath.Ext(fileName) == ".yml" {
 			continue
 		}
 
-		// NOTE: disabled for now, Refs https://github.com/helm/helm/issues/1463
+		linter.RunLinterRule(support.WarningSev, fileName, validateNoPlaintextSecrets(data))
+
 		// Check that all the templates have a matching value
-		// linter.RunLinterRule(support.WarningSev, fpath, validateNoMissingValues(templatesPath, valuesToRender, preExecutedTemplate))
+		linter.RunLinterRule(support.WarningSev, fileName, validateNoMissingValues(fileName, data, cvals))
 
 		// NOTE: disabled for now, Refs https://github.com/helm/helm/issues/1037
 		// linter.RunLinterRule(support.WarningSev, fpath, validateQuotes(string(preExecutedTemplate)))
 
-		renderedContent := renderedContentMap[path.Join(chart.Name(), fileName)]
-		if strings.TrimSpace(renderedContent) != "" {
-			linter.RunLinterRule(support.WarningSev, fpath, validateTopIndentLevel(renderedContent))
+		yamlTemplates[renderedPath(ch.Name(), fileName)] = fileName
+	}
 
-			decoder := yaml.NewYAMLOrJSONDecoder(strings.NewReader(renderedContent), 4096)
+	// Templates are validated one at a time as they are rendered so the
+	// rendered manifest is never held in memory as a whole.
+	var e engine.Engine
+	e.LintMode = true
+	resources := chartutil.ResourceIndex{}
+	err = e.RenderEach(renderChart, valuesToRender, func(name, renderedContent string) error {
+		if strings.TrimSpace(renderedContent) == "" {
+			return nil
+		}
+		fileName, ok := yamlTemplates[toSlash(name)]
+		if !ok {
+			// Sub-chart templates are not linted, but the resources they
+			// render can still collide with those of the chart.
+			if ext := path.Ext(name); ext == ".yaml" || ext == ".yml" {
+				resources.Add(toSlash(name), renderedContent)
+			}
+			return nil
+		}
+		resources.Add(fileName, renderedContent)
+		lintRenderedYaml(linter, fileName, renderedContent, namespace)
+		return nil
+	})
+	if !linter.RunLinterRule(support.ErrorSev, fpath, err) {
+		return
+	}
 
-			// Lint all resources if the file contains multiple documents separated by ---
-			for {
-				// Even though K8sYamlStruct only defines a few fields, an error in any other
-				// key will be raised as well
-				var yamlStruct *K8sYamlStruct
+	for _, dup := range resources.Duplicates() {
+		linter.RunLinterRule(support.ErrorSev, dup.Templates[len(dup.Templates)-1], errors.New(dup.String()))
+	}
+}
 
-				err := decoder.Decode(&yamlStruct)
-				if err == io.EOF {
-					break
-				}
+// notesTemplate returns the templates/NOTES.txt file of the chart, or nil if
+// it has none.
+func notesTemplate(ch *chart.Chart) *chart.File {
+	for _, t := range ch.Templates {
+		if toSlash(t.Name) == "templates/NOTES.txt" {
+			return t
+		}
+	}
+	return nil
+}
 
-				// If YAML linting fails, we sill progress. So we don't capture the returned state
-				// on this linter run.
-				linter.RunLinterRule(support.ErrorSev, fpath, validateYamlContent(err))
+// lintNotes renders NOTES.txt on its own and reports template errors against
+// it, warning when it renders to nothing.
+func lintNotes(linter *support.Linter, ch *chart.Chart, fileName string, values chartutil.Values) {
+	var e engine.Engine
+	e.LintMode = true
+	rendered, err := e.RenderTemplate(ch, values, renderedPath(ch.Name(), fileName))
+	if linter.RunLinterRule(support.ErrorSev, fileName, err) {
+		linter.RunLinterRule(support.WarningSev, fileName, validateNotesNotEmpty(rendered))
+	}
+}
 
-				if yamlStruct != nil {
-					// NOTE: set to warnings to allow users to support out-of-date kubernetes
-					// Refs https://github.com/helm/helm/issues/8596
-					linter.RunLinterRule(support.WarningSev, fpath, validateMetadataName(yamlStruct))
-					linter.RunLinterRule(support.WarningSev, fpath, validateNoDeprecations(yamlStruct))
+func validateNotesNotEmpty(rendered string) error {
+	if strings.TrimSpace(rendered) == "" {
+		return errors.New("NOTES.txt renders to nothing, remove it or add usage notes for the release")
+	}
+	return nil
+}
 
-					linter.RunLinterRule(support.ErrorSev, fpath, validateMatchSelector(yamlStruct, renderedContent))
-				}
-			}
+// lintRenderedYaml runs the rules that apply to the rendered content of a
+// YAML template.
+func lintRenderedYaml(linter *support.Linter, fpath, renderedContent, namespace string) {
+	linter.RunLinterRule(support.WarningSev, fpath, validateTopIndentLevel(renderedContent))
+	linter.RunLinterRule(support.ErrorSev, fpath, validateNoTabs(renderedContent))
+	linter.RunLinterRule(support.WarningSev, fpath, validateIndentConsistency(renderedContent))
+
+	decoder := yaml.NewYAMLOrJSONDecoder(strings.NewReader(renderedContent), 4096)
+
+	// Lint all resources if the file contains multiple documents separated by ---
+	for {
+		// Even though K8sYamlStruct only defines a few fields, an error in any other
+		// key will be raised as well
+		var yamlStruct *K8sYamlStruct
+
+		err := decoder.Decode(&yamlStruct)
+		if err == io.EOF {
+			break
+		}
+
+		// If YAML linting fails, we sill progress. So we don't capture the returned state
+		// on this linter run.
+		linter.RunLinterRule(support.ErrorSev, fpath, validateYamlContent(err))
+
+		if yamlStruct != nil {
+			linter.RunLinterRule(support.ErrorSev, fpath, validateRequiredK8sFields(yamlStruct))
+			// NOTE: set to warnings to allow users to support out-of-date kubernetes
+			// Refs https://github.com/helm/helm/issues/8596
+			linter.RunLinterRule(support.WarningSev, fpath, validateMetadataName(yamlStruct))
+			linter.RunLinterRule(support.WarningSev, fpath, validateNoDeprecations(yamlStruct))
+			linter.RunLinterRule(support.WarningSev, fpath, validateMetadataNamespace(yamlStruct, namespace))
+			linter.RunLinterRule(support.WarningSev, fpath, validateHookDeletePolicy(yamlStruct))
+
+			linter.RunLinterRule(support.ErrorSev, fpath, validateMatchSelector(yamlStruct, renderedContent))
 		}
 	}
 }
 
+// renderedPath returns the key under which the rendered content of a template
+// is stored, using forward slashes regardless of the separators in fileName.
+func renderedPath(chartName, fileName string) string {
+	return path.Join(chartName, toSlash(fileName))
+}
+
+// toSlash converts both Windows and Unix separators to forward slashes.
+//
+// filepath.ToSlash only converts the separator of the running OS, which would
+// leave backslashes alone when linting Windows-style paths elsewhere.
+func toSlash(name string) string {
+	return strings.ReplaceAll(name, "\\", "/")
+}
+
 // validateTopIndentLevel checks that the content does not start with an indent level > 0.
 //
 // This error can occur when a template accidentally inserts space. It can cause
@@ -178,6 +301,152 @@ func validateTopIndentLevel(content string) error {
 	return scanner.Err()
 }
 
+// validateNoTabs checks that no line of the content is indented with a tab.
+//
+// YAML forbids tabs in indentation, but a tab does not always make the parser
+// fail, as the line may be read as part of a scalar instead. Tabs in the
+// content of literal and folded block scalars are allowed.
+func validateNoTabs(content string) error {
+	scalar := -1
+	scanner := bufio.NewScanner(bytes.NewBufferString(content))
+	for n := 1; scanner.Scan(); n++ {
+		line := scanner.Text()
+		text := strings.TrimSpace(line)
+		indent := len(line) - len(strings.TrimLeft(line, " "))
+		if scalar >= 0 {
+			if text == "" || indent > scalar {
+				continue
+			}
+			scalar = -1
+		}
+		if text == "" {
+			continue
+		}
+		if strings.HasPrefix(line[indent:], "\t") {
+			return fmt.Errorf("line %d is indented with a tab, which YAML does not allow: %q", n, line)
+		}
+		if isBlockScalarHeader(text) {
+			scalar = indent
+		}
+	}
+	return scanner.Err()
+}
+
+// validateUTF8 checks that the template source is valid UTF-8.
+func validateUTF8(template []byte) error {
+	if utf8.Valid(template) {
+		return nil
+	}
+	line := 1 + bytes.Count(template[:invalidUTF8Offset(template)], []byte("\n"))
+	return fmt.Errorf("line %d is not valid UTF-8", line)
+}
+
+// invalidUTF8Offset returns the offset of the first invalid UTF-8 sequence in b.
+func invalidUTF8Offset(b []byte) int {
+	for i := 0; i < len(b); {
+		r, size := utf8.DecodeRune(b[i:])
+		if r == utf8.RuneError && size == 1 {
+			return i
+		}
+		i += size
+	}
+	return len(b)
+}
+
+// validateIndentConsistency warns about a mapping that is nested by a different
+// number of spaces than the other mappings in the file.
+//
+// YAML accepts any indentation, so a wrong `indent`/`nindent` count usually
+// renders into a valid document in which the block is nested where it was not
+// meant to be. This is a heuristic: the most common nesting step of the file is
+// taken as the intended one. Block scalars and sequences are not considered.
+func validateIndentConsistency(content string) error {
+	type block struct {
+		line, step int
+		key        string
+	}
+	var blocks []block
+	steps := map[int]int{}
+
+	// check reports the first block of the current document that deviates
+	// from its most common nesting step.
+	check := func() error {
+		common := 0
+		for step, count := range steps {
+			if count > steps[common] || (count == steps[common] && step < common) {
+				common = step
+			}
+		}
+		for _, b := range blocks {
+			if b.step != common {
+				return fmt.Errorf("line %d: %q is nested %d spaces deeper than its parent while the rest of the document uses %d, check the indent/nindent count", b.line, b.key, b.step, common)
+			}
+		}
+		blocks, steps = nil, map[int]int{}
+		return nil
+	}
+
+	opener, scalar := -1, -1
+	scanner := bufio.NewScanner(bytes.NewBufferString(content))
+	for n := 1; scanner.Scan(); n++ {
+		line := scanner.Text()
+		text := strings.TrimSpace(line)
+		indent := len(line) - len(strings.TrimLeft(line, " "))
+		if scalar >= 0 {
+			if text == "" || indent > scalar {
+				continue
+			}
+			scalar = -1
+		}
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		if text == "---" {
+			if err := check(); err != nil {
+				return err
+			}
+			opener = -1
+			continue
+		}
+
+		// The key of a sequence entry is indented past the dash.
+		keyIndent := indent
+		for strings.HasPrefix(line[keyIndent:], "- ") {
+			keyIndent += 2
+			keyIndent += len(line[keyIndent:]) - len(strings.TrimLeft(line[keyIndent:], " "))
+		}
+
+		if opener >= 0 && indent > opener && !strings.HasPrefix(text, "-") {
+			step := indent - opener
+			steps[step]++
+			blocks = append(blocks, block{line: n, step: step, key: strings.SplitN(text, ":", 2)[0]})
+		}
+
+		opener = -1
+		switch {
+		case strings.HasSuffix(text, ":"):
+			opener = keyIndent
+		case isBlockScalarHeader(text):
+			scalar = keyIndent
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	return check()
+}
+
+// isBlockScalarHeader reports whether a line ends with a literal or folded
+// block scalar indicator such as `key: |` or `key: >-`.
+func isBlockScalarHeader(text string) bool {
+	i := strings.LastIndex(text, ": ")
+	if i < 0 {
+		return false
+	}
+	indicator := strings.TrimSpace(text[i+1:])
+	return strings.HasPrefix(indicator, "|") || strings.HasPrefix(indicator, ">")
+}
+
 // Validation functions
 func validateTemplatesDir(templatesPath string) error {
 	if fi, err := os.Stat(templatesPath); err != nil {
@@ -205,6 +474,25 @@ func validateYamlContent(err error) error {
 	return errors.Wrap(err, "unable to parse YAML")
 }
 
+// validateRequiredK8sFields checks that a rendered document declares the
+// apiVersion and kind every Kubernetes object needs.
+func validateRequiredK8sFields(obj *K8sYamlStruct) error {
+	var missing []string
+	if obj.APIVersion == "" {
+		missing = append(missing, "apiVersion")
+	}
+	if obj.Kind == "" {
+		missing = append(missing, "kind")
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	if obj.Metadata.Name != "" {
+		return errors.Errorf("object %q is missing %s", obj.Metadata.Name, strings.Join(missing, " and "))
+	}
+	return errors.Errorf("object is missing %s", strings.Join(missing, " and "))
+}
+
 // validateMetadataName uses the correct validation function for the object
 // Kind, or if not set, defaults to the standard definition of a subdomain in
 // DNS (RFC 1123), used by most resources.
@@ -220,6 +508,66 @@ func validateMetadataName(obj *K8sYamlStruct) error {
 	return nil
 }
 
+// clusterScopedKinds lists the kinds of the built-in cluster-scoped resources,
+// in lower case.
+var clusterScopedKinds = map[string]bool{
+	"namespace":                      true,
+	"node":                           true,
+	"persistentvolume":               true,
+	"componentstatus":                true,
+	"clusterrole":                    true,
+	"clusterrolebinding":             true,
+	"customresourcedefinition":       true,
+	"apiservice":                     true,
+	"mutatingwebhookconfiguration":   true,
+	"validatingwebhookconfiguration": true,
+	"storageclass":                   true,
+	"csidriver":                      true,
+	"csinode":                        true,
+	"volumeattachment":               true,
+	"priorityclass":                  true,
+	"runtimeclass":                   true,
+	"ingressclass":                   true,
+	"podsecuritypolicy":              true,
+	"certificatesigningrequest":      true,
+}
+
+// validateMetadataNamespace warns about namespaced resources that set a
+// namespace other than the release namespace. Namespaces should come from the
+// release, so that the chart can be installed in any namespace, and a
+// namespace rendered from {{ .Release.Namespace }} is not reported.
+func validateMetadataNamespace(obj *K8sYamlStruct, releaseNamespace string) error {
+	if obj.Metadata.Namespace == "" || obj.Metadata.Namespace == releaseNamespace || clusterScopedKinds[strings.ToLower(obj.Kind)] {
+		return nil
+	}
+	return fmt.Errorf("%s %q sets metadata.namespace to %q, use {{ .Release.Namespace }} so that the release namespace is used", obj.Kind, obj.Metadata.Name, obj.Metadata.Namespace)
+}
+
+// validateHookDeletePolicy checks that the helm.sh/hook-delete-policy
+// annotation only lists known policies. Unknown policies are ignored when the
+// hook runs, so a misspelled one leaves the hook resource behind.
+func validateHookDeletePolicy(obj *K8sYamlStruct) error {
+	policies, ok := obj.Metadata.Annotations[release.HookDeleteAnnotation]
+	if !ok {
+		return nil
+	}
+	var unknown []string
+	for _, p := range strings.Split(policies, ",") {
+		// Policies are matched the same way as when hooks are sorted
+		p = strings.ToLower(strings.TrimSpace(p))
+		switch release.HookDeletePolicy(p) {
+		case "", release.HookSucceeded, release.HookFailed, release.HookBeforeHookCreation:
+		default:
+			unknown = append(unknown, strconv.Quote(p))
+		}
+	}
+	if len(unknown) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%s %q has unknown %s %s, use %s, %s or %s", obj.Kind, obj.Metadata.Name, release.HookDeleteAnnotation,
+		strings.Join(unknown, ", "), release.HookBeforeHookCreation, release.HookSucceeded, release.HookFailed)
+}
+
 // validateMetadataNameFunc will return a name validation function for the
 // object kind, if defined below.
 //
@@ -281,6 +629,436 @@ func validateNoReleaseTime(manifest []byte) error {
 	return nil
 }
 
+// collectDefines records the file defining each named template of the chart
+// and of its sub-charts, keeping the first definition found for a name.
+func collectDefines(c *chart.Chart, prefix string, defines map[string]string) {
+	for _, template := range c.Templates {
+		for _, m := range defineSearch.FindAllSubmatch(template.Data, -1) {
+			if _, ok := defines[string(m[1])]; !ok {
+				defines[string(m[1])] = path.Join(prefix, toSlash(template.Name))
+			}
+		}
+	}
+	for _, dep := range c.Dependencies() {
+		collectDefines(dep, path.Join(prefix, "charts", dep.Name()), defines)
+	}
+}
+
+// validateNoDefineCollisions warns about named templates that are also defined
+// by a sub-chart. Named templates share a single namespace across a chart and
+// its sub-charts, so only one of the definitions is used for both.
+func validateNoDefineCollisions(template []byte, subchartDefines map[string]string) error {
+	var collisions []string
+	for _, m := range defineSearch.FindAllSubmatch(template, -1) {
+		if file, ok := subchartDefines[string(m[1])]; ok {
+			collisions = append(collisions, fmt.Sprintf("%q (also defined in %s)", m[1], file))
+		}
+	}
+	if len(collisions) == 0 {
+		return nil
+	}
+	return fmt.Errorf("named templates collide with sub-chart templates: %s, prefix them with the chart name", strings.Join(collisions, ", "))
+}
+
+// guardFunctions lists the template functions that handle a value that is not
+// set, so the values referenced in their pipeline are not reported as missing.
+var guardFunctions = map[string]bool{
+	"default":  true,
+	"coalesce": true,
+	"empty":    true,
+	"required": true,
+	"hasKey":   true,
+}
+
+// validateNoMissingValues warns about references to .Values in the template
+// that have no value in the chart's values, and so always render empty.
+//
+// References used with default or a similar function, or assigned to a
+// variable, are not reported. Neither are the references in an if, with or
+// range whose condition reads .Values, as their content is only rendered for
+// some values. Inside with and range, where the dot is rebound, and in named
+// templates, where it is unknown, only references through $ are checked.
+func validateNoMissingValues(name string, template []byte, values chartutil.Values) error {
+	trees, err := parse.Parse(name, string(template), "", "", templatefuncs.FuncMap())
+	if err != nil {
+		// Parse errors are reported when the template is rendered
+		return nil
+	}
+	tree, ok := trees[name]
+	if !ok || tree.Root == nil {
+		return nil
+	}
+
+	w := &valuesWalker{values: values, seen: map[string]bool{}}
+	w.walk(tree.Root, true)
+	if len(w.missing) == 0 {
+		return nil
+	}
+	return fmt.Errorf("values are referenced but not set: %s, set them in values.yaml or use default", strings.Join(w.missing, ", "))
+}
+
+// valuesWalker collects the .Values references of a template that are not set.
+type valuesWalker struct {
+	values  chartutil.Values
+	seen    map[string]bool
+	missing []string
+}
+
+// walk visits node. dotIsRoot reports whether the dot is the top-level scope
+// of the template.
+func (w *valuesWalker) walk(node parse.Node, dotIsRoot bool) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, c := range n.Nodes {
+			w.walk(c, dotIsRoot)
+		}
+	case *parse.ActionNode:
+		// A value assigned to a variable is usually tested before it is used
+		if len(n.Pipe.Decl) == 0 {
+			w.walkPipe(n.Pipe, dotIsRoot)
+		}
+	case *parse.TemplateNode:
+		w.walkPipe(n.Pipe, dotIsRoot)
+	case *parse.IfNode:
+		w.walkBranches(&n.BranchNode, dotIsRoot, dotIsRoot)
+	case *parse.WithNode:
+		w.walkBranches(&n.BranchNode, false, dotIsRoot)
+	case *parse.RangeNode:
+		w.walkBranches(&n.BranchNode, false, dotIsRoot)
+	}
+}
+
+// walkBranches visits the lists of an if, with or range, unless its condition
+// reads .Values. listDotIsRoot reports whether the dot is the top-level scope
+// in the main list.
+func (w *valuesWalker) walkBranches(n *parse.BranchNode, listDotIsRoot, dotIsRoot bool) {
+	if len(w.pipeRefs(n.Pipe, dotIsRoot)) > 0 {
+		return
+	}
+	w.walk(n.List, listDotIsRoot)
+	w.walk(n.ElseList, dotIsRoot)
+}
+
+// walkPipe records the references of a pipeline that are not set, unless the
+// pipeline uses one of the guardFunctions.
+func (w *valuesWalker) walkPipe(pipe *parse.PipeNode, dotIsRoot bool) {
+	if pipe == nil || usesGuardFunction(pipe) {
+		return
+	}
+	for _, ref := range w.pipeRefs(pipe, dotIsRoot) {
+		if ref == "" || w.seen[ref] || isSetValue(w.values, strings.Split(ref, ".")) {
+			continue
+		}
+		w.seen[ref] = true
+		w.missing = append(w.missing, ".Values."+ref)
+	}
+}
+
+// pipeRefs returns the dotted paths below .Values referenced by a pipeline.
+// A bare .Values is returned as an empty path.
+func (w *valuesWalker) pipeRefs(pipe *parse.PipeNode, dotIsRoot bool) []string {
+	var refs []string
+	var visit func(node parse.Node)
+	visit = func(node parse.Node) {
+		switch n := node.(type) {
+		case *parse.PipeNode:
+			if n == nil {
+				return
+			}
+			for _, cmd := range n.Cmds {
+				for _, arg := range cmd.Args {
+					visit(arg)
+				}
+			}
+		case *parse.FieldNode:
+			if dotIsRoot && n.Ident[0] == "Values" {
+				refs = append(refs, strings.Join(n.Ident[1:], "."))
+			}
+		case *parse.VariableNode:
+			if len(n.Ident) > 1 && n.Ident[0] == "$" && n.Ident[1] == "Values" {
+				refs = append(refs, strings.Join(n.Ident[2:], "."))
+			}
+		}
+	}
+	visit(pipe)
+	return refs
+}
+
+// usesGuardFunction reports whether a pipeline, or a pipeline nested in it,
+// calls one of the guardFunctions.
+func usesGuardFunction(pipe *parse.PipeNode) bool {
+	for _, cmd := range pipe.Cmds {
+		for _, arg := range cmd.Args {
+			switch a := arg.(type) {
+			case *parse.IdentifierNode:
+				if guardFunctions[a.Ident] {
+					return true
+				}
+			case *parse.PipeNode:
+				if usesGuardFunction(a) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// isSetValue reports whether the values have a key at path. Paths that go
+// through something other than a map cannot be checked and are reported as set.
+func isSetValue(values map[string]interface{}, path []string) bool {
+	v, ok := values[path[0]]
+	if !ok {
+		return false
+	}
+	if len(path) == 1 {
+		return true
+	}
+	switch next := v.(type) {
+	case map[string]interface{}:
+		return isSetValue(next, path[1:])
+	case chartutil.Values:
+		return isSetValue(next, path[1:])
+	default:
+		return true
+	}
+}
+
+// validateOverridesUsed warns about override values, such as those given with
+// --set or -f, that have no effect because the chart does not know them.
+//
+// A value is known if it has a default in the chart or in a sub-chart, is read
+// by a template, or is a dependency condition. Values below a known value that
+// is not a map, such as one passed to toYaml, are known too. The global and
+// tags tables are not checked.
+func validateOverridesUsed(ch *chart.Chart, overrides map[string]interface{}) error {
+	if len(overrides) == 0 {
+		return nil
+	}
+	defaults, err := chartutil.CoalesceValues(ch, nil)
+	if err != nil {
+		return nil
+	}
+	referenced := map[string]bool{}
+	collectReferencedValues(ch, "", referenced)
+
+	var unused []string
+	var check func(overrides, defaults map[string]interface{}, prefix string)
+	check = func(overrides, defaults map[string]interface{}, prefix string) {
+		keys := make([]string, 0, len(overrides))
+		for k := range overrides {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			p := prefix + k
+			if prefix == "" && (k == chartutil.GlobalKey || k == "tags") {
+				continue
+			}
+			if referenced[p] {
+				continue
+			}
+			if d, ok := defaults[k]; ok {
+				dm, dok := d.(map[string]interface{})
+				om, ook := overrides[k].(map[string]interface{})
+				if dok && ook {
+					check(om, dm, p+".")
+				}
+				continue
+			}
+			if om, ok := overrides[k].(map[string]interface{}); ok && hasReferenceBelow(referenced, p) {
+				check(om, nil, p+".")
+				continue
+			}
+			unused = append(unused, ".Values."+p)
+		}
+	}
+	check(overrides, defaults, "")
+
+	if len(unused) == 0 {
+		return nil
+	}
+	return fmt.Errorf("overrides have no effect as the chart does not use them: %s", strings.Join(unused, ", "))
+}
+
+// hasReferenceBelow reports whether a value below prefix is referenced.
+func hasReferenceBelow(referenced map[string]bool, prefix string) bool {
+	for r := range referenced {
+		if strings.HasPrefix(r, prefix+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// collectReferencedValues adds the dotted paths of the values read by the
+// templates of c and its sub-charts, and of the conditions of its
+// dependencies, to referenced. prefix is where the values of c live.
+func collectReferencedValues(c *chart.Chart, prefix string, referenced map[string]bool) {
+	if c.Metadata != nil {
+		for _, dep := range c.Metadata.Dependencies {
+			for _, cond := range strings.Split(dep.Condition, ",") {
+				if cond = strings.TrimSpace(cond); cond != "" {
+					referenced[prefix+cond] = true
+				}
+			}
+		}
+	}
+	for _, t := range c.Templates {
+		trees, err := parse.Parse(t.Name, string(t.Data), "", "", templatefuncs.FuncMap())
+		if err != nil {
+			continue
+		}
+		for _, tree := range trees {
+			if tree.Root != nil {
+				collectNodeValues(tree.Root, prefix, referenced)
+			}
+		}
+	}
+	for _, dep := range c.Dependencies() {
+		collectReferencedValues(dep, prefix+dep.Name()+".", referenced)
+	}
+}
+
+// collectNodeValues adds the .Values and $.Values references below node to
+// referenced, whatever the dot is bound to.
+func collectNodeValues(node parse.Node, prefix string, referenced map[string]bool) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, c := range n.Nodes {
+			collectNodeValues(c, prefix, referenced)
+		}
+	case *parse.ActionNode:
+		collectNodeValues(n.Pipe, prefix, referenced)
+	case *parse.TemplateNode:
+		collectNodeValues(n.Pipe, prefix, referenced)
+	case *parse.IfNode:
+		collectBranchValues(&n.BranchNode, prefix, referenced)
+	case *parse.WithNode:
+		collectBranchValues(&n.BranchNode, prefix, referenced)
+	case *parse.RangeNode:
+		collectBranchValues(&n.BranchNode, prefix, referenced)
+	case *parse.PipeNode:
+		if n == nil {
+			return
+		}
+		for _, cmd := range n.Cmds {
+			for _, arg := range cmd.Args {
+				collectNodeValues(arg, prefix, referenced)
+			}
+		}
+	case *parse.FieldNode:
+		if len(n.Ident) > 1 && n.Ident[0] == "Values" {
+			referenced[prefix+strings.Join(n.Ident[1:], ".")] = true
+		}
+	case *parse.VariableNode:
+		if len(n.Ident) > 2 && n.Ident[0] == "$" && n.Ident[1] == "Values" {
+			referenced[prefix+strings.Join(n.Ident[2:], ".")] = true
+		}
+	}
+}
+
+func collectBranchValues(n *parse.BranchNode, prefix string, referenced map[string]bool) {
+	collectNodeValues(n.Pipe, prefix, referenced)
+	collectNodeValues(n.List, prefix, referenced)
+	collectNodeValues(n.ElseList, prefix, referenced)
+}
+
+// validateNoPlaintextSecrets warns about Secrets whose data or stringData entries
+// are written out literally in the template instead of being set from a template
+// action such as {{ .Values.password }}. This is a heuristic on the template
+// source, as the rendered manifest no longer shows where a value came from.
+func validateNoPlaintextSecrets(template []byte) error {
+	var literal []string
+	for _, doc := range documentSeparator.Split(string(template), -1) {
+		if !secretKindSearch.MatchString(doc) {
+			continue
+		}
+		literal = append(literal, literalSecretKeys(doc)...)
+	}
+	if len(literal) == 0 {
+		return nil
+	}
+	return fmt.Errorf("literal values are set for Secret keys %s, consider setting them from .Values", strings.Join(literal, ", "))
+}
+
+// literalSecretKeys returns the data and stringData keys of a Secret document
+// whose values do not contain a template action.
+func literalSecretKeys(doc string) []string {
+	var (
+		keys        []string
+		section     string
+		entryIndent = -1
+		key         string
+		value       []string
+	)
+	flush := func() {
+		if key != "" && isLiteralSecretValue(value) {
+			keys = append(keys, fmt.Sprintf("%s.%s", section, key))
+		}
+		key, value = "", nil
+	}
+
+	for _, line := range strings.Split(doc, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") || strings.HasPrefix(trimmed, "{{") && strings.HasSuffix(trimmed, "}}") && !strings.Contains(trimmed, ":") {
+			continue
+		}
+		indent := len(line) - len(strings.TrimLeft(line, " "))
+
+		if indent == 0 {
+			flush()
+			section, entryIndent = "", -1
+			if m := secretDataSearch.FindStringSubmatch(trimmed); m != nil {
+				section = m[1]
+			}
+			continue
+		}
+		if section == "" {
+			continue
+		}
+		if entryIndent == -1 {
+			entryIndent = indent
+		}
+		if indent == entryIndent {
+			flush()
+			if m := secretEntrySearch.FindStringSubmatch(trimmed); m != nil {
+				key, value = m[1], []string{m[2]}
+			}
+			continue
+		}
+		if key != "" && indent > entryIndent {
+			// Continuation of a multi-line value
+			value = append(value, trimmed)
+		}
+	}
+	flush()
+	return keys
+}
+
+// isLiteralSecretValue reports whether the lines of a value hold data that was
+// not produced by a template action.
+func isLiteralSecretValue(value []string) bool {
+	hasData := false
+	for _, v := range value {
+		if strings.Contains(v, "{{") {
+			return false
+		}
+		switch strings.Trim(v, `"'`) {
+		case "", "|", "|-", "|+", ">", ">-", ">+":
+			continue
+		}
+		hasData = true
+	}
+	return hasData
+}
+
 // validateMatchSelector ensures that template specs have a selector declared.
 // See https://github.com/helm/helm/issues/1990
 func validateMatchSelector(yamlStruct *K8sYamlStruct, manifest string) error {
@@ -305,6 +1083,7 @@ type K8sYamlStruct struct {
 }
 
 type k8sYamlMetadata struct {
-	Namespace string
-	Name      string
+	Namespace   string
+	Name        string
+	Annotations map[string]string
 }
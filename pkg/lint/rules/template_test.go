@@ -27,6 +27,7 @@ import (
 	"helm.sh/helm/v3/pkg/chart"
 	"helm.sh/helm/v3/pkg/chartutil"
 	"helm.sh/helm/v3/pkg/lint/support"
+	"helm.sh/helm/v3/pkg/release"
 )
 
 const templateTestBasedir = "./testdata/albatross"
@@ -391,6 +392,132 @@ func TestValidateTopIndentLevel(t *testing.T) {
 
 }
 
+func TestValidateNoTabs(t *testing.T) {
+	for doc, expected := range map[string]string{
+		"apiVersion: v1\nkind: ConfigMap\n":                               "",
+		"data:\n  key: \"a\\tb\"\n":                                       "",
+		"data:\n  Makefile: |\n    all:\n    \tgo build\nkind: ConfigMap": "",
+		"\t\n":                                  "",
+		"metadata:\n\tname: foo\n":              "line 2 is indented with a tab, which YAML does not allow: \"\\tname: foo\"",
+		"metadata:\n  labels:\n  \tapp: foo\n":  "line 3 is indented with a tab, which YAML does not allow: \"  \\tapp: foo\"",
+		"data: |\n  ok\nspec:\n\treplicas: 1\n": "line 4 is indented with a tab, which YAML does not allow: \"\\treplicas: 1\"",
+	} {
+		err := validateNoTabs(doc)
+		if expected == "" {
+			if err != nil {
+				t.Errorf("Expected no error for %q, got %q", doc, err)
+			}
+		} else if err == nil || err.Error() != expected {
+			t.Errorf("Expected %q for %q, got %v", expected, doc, err)
+		}
+	}
+}
+
+func TestValidateUTF8(t *testing.T) {
+	if err := validateUTF8([]byte("name: caf\xc3\xa9\n")); err != nil {
+		t.Errorf("Expected valid UTF-8, got %q", err)
+	}
+	err := validateUTF8([]byte("a: 1\nname: caf\xe9\n"))
+	if err == nil || err.Error() != "line 2 is not valid UTF-8" {
+		t.Errorf("Expected an error on line 2, got %v", err)
+	}
+}
+
+func TestTabIndentedTemplate(t *testing.T) {
+	mychart := chart.Chart{
+		Metadata: &chart.Metadata{
+			APIVersion: "v2",
+			Name:       "tabs",
+			Version:    "0.1.0",
+			Icon:       "satisfy-the-linting-gods.gif",
+		},
+		Templates: []*chart.File{
+			{Name: "templates/configmap.yaml", Data: []byte("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: {{ .Release.Name }}\ndata:\n\tkey: value\n")},
+		},
+	}
+	tmpdir := ensure.TempDir(t)
+	defer os.RemoveAll(tmpdir)
+
+	if err := chartutil.SaveDir(&mychart, tmpdir); err != nil {
+		t.Fatal(err)
+	}
+
+	linter := support.Linter{ChartDir: filepath.Join(tmpdir, mychart.Name())}
+	Templates(&linter, values, namespace, strict)
+	for _, msg := range linter.Messages {
+		if msg.Path == "templates/configmap.yaml" && msg.Severity == support.ErrorSev && strings.HasPrefix(msg.Err.Error(), "line 6 is indented with a tab") {
+			return
+		}
+	}
+	t.Errorf("Expected an error for the tab on line 6, got %v", linter.Messages)
+}
+
+func TestValidateIndentConsistency(t *testing.T) {
+	for doc, shouldFail := range map[string]bool{
+		// Should not fail
+		"":                                     false,
+		"apiVersion: v1\nkind: ConfigMap\n":    false,
+		"metadata:\n  labels:\n    app: foo\n": false,
+		"spec:\n  containers:\n  - name: foo\n    ports:\n    - containerPort: 80\n": false,
+		"data:\n  script: |\n      echo indented\n  other: value\n":                  false,
+		"a:\n  b: c\n---\nd:\n    e: f\n---\ng:\n  h: i\n":                           false,
+		// Should fail
+		"metadata:\n  labels:\n    app: foo\n  annotations:\n        a: b\n":   true,
+		"spec:\n  template:\n    metadata:\n      labels:\n          app: x\n": true,
+	} {
+		if err := validateIndentConsistency(doc); (err == nil) == shouldFail {
+			t.Errorf("Expected %t for %q, got %v", shouldFail, doc, err)
+		}
+	}
+}
+
+func TestIndentMisuse(t *testing.T) {
+	mychart := chart.Chart{
+		Metadata: &chart.Metadata{
+			APIVersion: "v2",
+			Name:       "misindented",
+			Version:    "0.1.0",
+			Icon:       "satisfy-the-linting-gods.gif",
+		},
+		Templates: []*chart.File{
+			{
+				Name: "templates/configmap.yaml",
+				Data: []byte(`apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: misindented
+  labels:
+    {{- "app: foo" | nindent 6 }}
+data:
+  key: value
+`),
+			},
+		},
+	}
+	tmpdir := ensure.TempDir(t)
+	defer os.RemoveAll(tmpdir)
+
+	if err := chartutil.SaveDir(&mychart, tmpdir); err != nil {
+		t.Fatal(err)
+	}
+
+	linter := support.Linter{ChartDir: filepath.Join(tmpdir, mychart.Name())}
+	Templates(&linter, values, namespace, strict)
+	if l := len(linter.Messages); l != 1 {
+		for i, msg := range linter.Messages {
+			t.Logf("Message %d: %s", i, msg)
+		}
+		t.Fatalf("Expected 1 lint warning, got %d", l)
+	}
+	msg := linter.Messages[0]
+	if msg.Severity != support.WarningSev {
+		t.Errorf("Expected a warning, got severity %d", msg.Severity)
+	}
+	if !strings.Contains(msg.Err.Error(), `line 6: "app" is nested 4 spaces deeper than its parent while the rest of the document uses 2`) {
+		t.Errorf("Unexpected message: %s", msg.Err)
+	}
+}
+
 // TestEmptyWithCommentsManifests checks the lint is not failing against empty manifests that contains only comments
 // See https://github.com/helm/helm/issues/8621
 func TestEmptyWithCommentsManifests(t *testing.T) {
@@ -424,3 +551,707 @@ func TestEmptyWithCommentsManifests(t *testing.T) {
 		t.Fatalf("Expected 0 lint errors, got %d", l)
 	}
 }
+
+func TestTemplatesReleaseName(t *testing.T) {
+	mychart := chart.Chart{
+		Metadata: &chart.Metadata{
+			APIVersion: "v2",
+			Name:       "releasename",
+			Version:    "0.1.0",
+			Icon:       "satisfy-the-linting-gods.gif",
+		},
+		Templates: []*chart.File{
+			{
+				Name: "templates/configmap.yaml",
+				Data: []byte("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: {{ .Release.Name }}_config\n"),
+			},
+		},
+	}
+	tmpdir := ensure.TempDir(t)
+	defer os.RemoveAll(tmpdir)
+
+	if err := chartutil.SaveDir(&mychart, tmpdir); err != nil {
+		t.Fatal(err)
+	}
+
+	for releaseName, expect := range map[string]string{
+		"":           `"test-release_config"`,
+		"my-release": `"my-release_config"`,
+	} {
+		linter := support.Linter{ChartDir: filepath.Join(tmpdir, mychart.Name()), ReleaseName: releaseName}
+		Templates(&linter, values, namespace, strict)
+		if len(linter.Messages) != 1 {
+			t.Fatalf("Expected 1 lint message for release name %q, got %v", releaseName, linter.Messages)
+		}
+		if msg := linter.Messages[0].Err.Error(); !strings.Contains(msg, expect) {
+			t.Errorf("Expected the rendered name %s in %q", expect, msg)
+		}
+	}
+}
+
+func TestRenderedPath(t *testing.T) {
+	for fileName, renderedName := range map[string]string{
+		`templates\deployment.yaml`: `mychart\templates\deployment.yaml`,
+		"templates/deployment.yaml": `mychart\templates\deployment.yaml`,
+		`templates\service.yaml`:    "mychart/templates/service.yaml",
+		"templates/service.yaml":    "mychart/templates/service.yaml",
+	} {
+		if got, want := renderedPath("mychart", fileName), toSlash(renderedName); got != want {
+			t.Errorf("Expected %q to resolve to %q, got %q", fileName, want, got)
+		}
+	}
+}
+
+func TestValidateNoPlaintextSecrets(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		expected string
+	}{{
+		name: "literal stringData and data",
+		template: `apiVersion: v1
+kind: Secret
+metadata:
+  name: {{ .Release.Name }}
+stringData:
+  username: admin
+  password: "hunter2"
+  token: {{ .Values.token | quote }}
+data:
+  key: aHVudGVyMg==
+`,
+		expected: "literal values are set for Secret keys stringData.username, stringData.password, data.key, consider setting them from .Values",
+	}, {
+		name: "templated values",
+		template: `apiVersion: v1
+kind: Secret
+metadata:
+  name: {{ .Release.Name }}
+stringData:
+  password: {{ required "a password is required" .Values.password | quote }}
+  {{- if .Values.extra }}
+  extra: {{ .Values.extra }}
+  {{- end }}
+  config.yaml: |
+    user: {{ .Values.user }}
+data:
+  key: {{ .Values.key | b64enc }}
+  {{- range $k, $v := .Values.more }}
+  {{ $k }}: {{ $v | b64enc }}
+  {{- end }}
+`,
+	}, {
+		name: "literal block scalar",
+		template: `apiVersion: v1
+kind: Secret
+metadata:
+  name: creds
+stringData:
+  credentials: |
+    aws_secret_access_key = abc123
+`,
+		expected: "literal values are set for Secret keys stringData.credentials, consider setting them from .Values",
+	}, {
+		name: "literal data outside of a Secret",
+		template: `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+data:
+  password: not-a-secret
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: empty
+type: Opaque
+`,
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateNoPlaintextSecrets([]byte(tt.template))
+			if tt.expected == "" {
+				if err != nil {
+					t.Errorf("Expected no error, got %s", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.expected {
+				t.Errorf("Expected %q, got %v", tt.expected, err)
+			}
+		})
+	}
+}
+
+func TestDuplicateResources(t *testing.T) {
+	service := []byte(`apiVersion: v1
+kind: Service
+metadata:
+  name: {{ .Release.Name }}-web
+spec:
+  ports:
+  - port: 80
+`)
+	mychart := chart.Chart{
+		Metadata: &chart.Metadata{
+			APIVersion: "v2",
+			Name:       "duplicates",
+			Version:    "0.1.0",
+			Icon:       "satisfy-the-linting-gods.gif",
+		},
+		Templates: []*chart.File{
+			{Name: "templates/service.yaml", Data: service},
+			{Name: "templates/service-copy.yaml", Data: service},
+		},
+	}
+	tmpdir := ensure.TempDir(t)
+	defer os.RemoveAll(tmpdir)
+
+	if err := chartutil.SaveDir(&mychart, tmpdir); err != nil {
+		t.Fatal(err)
+	}
+
+	linter := support.Linter{ChartDir: filepath.Join(tmpdir, mychart.Name())}
+	Templates(&linter, values, namespace, strict)
+	if l := len(linter.Messages); l != 1 {
+		for i, msg := range linter.Messages {
+			t.Logf("Message %d: %s", i, msg)
+		}
+		t.Fatalf("Expected 1 lint error, got %d", l)
+	}
+	msg := linter.Messages[0]
+	if msg.Severity != support.ErrorSev {
+		t.Errorf("Expected an error, got severity %d", msg.Severity)
+	}
+	expected := `Service "test-release-web" is rendered more than once, by templates/service-copy.yaml, templates/service.yaml`
+	if msg.Err.Error() != expected {
+		t.Errorf("Expected %q, got %q", expected, msg.Err)
+	}
+}
+
+func TestValidateNoDefineCollisions(t *testing.T) {
+	helpers := []byte(`{{- define "fullname" -}}
+{{ .Release.Name }}-{{ .Chart.Name }}
+{{- end -}}
+`)
+	mychart := chart.Chart{
+		Metadata: &chart.Metadata{
+			APIVersion: "v2",
+			Name:       "parent",
+			Version:    "0.1.0",
+			Icon:       "satisfy-the-linting-gods.gif",
+		},
+		Templates: []*chart.File{
+			{Name: "templates/_helpers.tpl", Data: append(helpers, []byte(`{{- define "parent.labels" -}}
+app: parent
+{{- end -}}
+`)...)},
+		},
+	}
+	subchart := &chart.Chart{
+		Metadata: &chart.Metadata{
+			APIVersion: "v2",
+			Name:       "child",
+			Version:    "0.1.0",
+		},
+		Templates: []*chart.File{
+			{Name: "templates/_helpers.tpl", Data: helpers},
+		},
+	}
+	mychart.AddDependency(subchart)
+
+	tmpdir := ensure.TempDir(t)
+	defer os.RemoveAll(tmpdir)
+
+	if err := chartutil.SaveDir(&mychart, tmpdir); err != nil {
+		t.Fatal(err)
+	}
+
+	linter := support.Linter{ChartDir: filepath.Join(tmpdir, mychart.Name())}
+	Templates(&linter, values, namespace, strict)
+	if l := len(linter.Messages); l != 1 {
+		for i, msg := range linter.Messages {
+			t.Logf("Message %d: %s", i, msg)
+		}
+		t.Fatalf("Expected 1 lint warning, got %d", l)
+	}
+	msg := linter.Messages[0]
+	if msg.Severity != support.WarningSev {
+		t.Errorf("Expected a warning, got severity %d", msg.Severity)
+	}
+	if msg.Path != "templates/_helpers.tpl" {
+		t.Errorf("Expected the warning on templates/_helpers.tpl, got %s", msg.Path)
+	}
+	expected := `named templates collide with sub-chart templates: "fullname" (also defined in charts/child/templates/_helpers.tpl), prefix them with the chart name`
+	if msg.Err.Error() != expected {
+		t.Errorf("Expected %q, got %q", expected, msg.Err)
+	}
+}
+
+func TestMetadataNamespace(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		expected string
+	}{
+		{
+			name: "hardcoded namespace",
+			template: `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+  namespace: production
+`,
+			expected: `ConfigMap "settings" sets metadata.namespace to "production", use {{ .Release.Namespace }} so that the release namespace is used`,
+		},
+		{
+			name: "release namespace",
+			template: `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+  namespace: {{ .Release.Namespace }}
+`,
+		},
+		{
+			name: "no namespace",
+			template: `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+`,
+		},
+		{
+			name: "cluster-scoped kind",
+			template: `apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRole
+metadata:
+  name: reader
+  namespace: production
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mychart := chart.Chart{
+				Metadata: &chart.Metadata{
+					APIVersion: "v2",
+					Name:       "namespaced",
+					Version:    "0.1.0",
+					Icon:       "satisfy-the-linting-gods.gif",
+				},
+				Templates: []*chart.File{
+					{Name: "templates/resource.yaml", Data: []byte(tt.template)},
+				},
+			}
+			tmpdir := ensure.TempDir(t)
+			defer os.RemoveAll(tmpdir)
+
+			if err := chartutil.SaveDir(&mychart, tmpdir); err != nil {
+				t.Fatal(err)
+			}
+
+			linter := support.Linter{ChartDir: filepath.Join(tmpdir, mychart.Name())}
+			Templates(&linter, values, namespace, strict)
+			if tt.expected == "" {
+				if len(linter.Messages) != 0 {
+					t.Errorf("Expected no lint messages, got %v", linter.Messages)
+				}
+				return
+			}
+			if l := len(linter.Messages); l != 1 {
+				t.Fatalf("Expected 1 lint warning, got %d: %v", l, linter.Messages)
+			}
+			msg := linter.Messages[0]
+			if msg.Severity != support.WarningSev {
+				t.Errorf("Expected a warning, got severity %d", msg.Severity)
+			}
+			if msg.Err.Error() != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, msg.Err)
+			}
+		})
+	}
+}
+
+func TestMissingKind(t *testing.T) {
+	manifests := []byte(`apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: complete
+---
+apiVersion: v1
+metadata:
+  name: forgotten
+`)
+	mychart := chart.Chart{
+		Metadata: &chart.Metadata{
+			APIVersion: "v2",
+			Name:       "missingkind",
+			Version:    "0.1.0",
+			Icon:       "satisfy-the-linting-gods.gif",
+		},
+		Templates: []*chart.File{
+			{Name: "templates/configmaps.yaml", Data: manifests},
+		},
+	}
+	tmpdir := ensure.TempDir(t)
+	defer os.RemoveAll(tmpdir)
+
+	if err := chartutil.SaveDir(&mychart, tmpdir); err != nil {
+		t.Fatal(err)
+	}
+
+	linter := support.Linter{ChartDir: filepath.Join(tmpdir, mychart.Name())}
+	Templates(&linter, values, namespace, strict)
+	if l := len(linter.Messages); l != 1 {
+		for i, msg := range linter.Messages {
+			t.Logf("Message %d: %s", i, msg)
+		}
+		t.Fatalf("Expected 1 lint error, got %d", l)
+	}
+	msg := linter.Messages[0]
+	if msg.Severity != support.ErrorSev {
+		t.Errorf("Expected an error, got severity %d", msg.Severity)
+	}
+	if msg.Path != "templates/configmaps.yaml" {
+		t.Errorf("Expected the error on templates/configmaps.yaml, got %s", msg.Path)
+	}
+	expected := `object "forgotten" is missing kind`
+	if msg.Err.Error() != expected {
+		t.Errorf("Expected %q, got %q", expected, msg.Err)
+	}
+}
+
+func TestTemplatesKubeVersion(t *testing.T) {
+	pdb := []byte(`{{- if semverCompare ">=1.21-0" .Capabilities.KubeVersion.Version }}
+apiVersion: policy/v1
+kind: PodDisruptionBudget
+metadata:
+  name: {{ .Release.Name }}
+spec:
+  minAvailable: 1
+{{- else }}
+apiVersion: policy/v1beta1
+kind: PodDisruptionBudget
+metadata:
+  name: {{ .Release.Name }}
+  namespace: kube-system
+spec:
+  minAvailable: 1
+{{- end }}
+`)
+	mychart := chart.Chart{
+		Metadata: &chart.Metadata{
+			APIVersion: "v2",
+			Name:       "kubeversion",
+			Version:    "0.1.0",
+			Icon:       "satisfy-the-linting-gods.gif",
+		},
+		Templates: []*chart.File{
+			{Name: "templates/pdb.yaml", Data: pdb},
+		},
+	}
+	tmpdir := ensure.TempDir(t)
+	defer os.RemoveAll(tmpdir)
+
+	if err := chartutil.SaveDir(&mychart, tmpdir); err != nil {
+		t.Fatal(err)
+	}
+	chartDir := filepath.Join(tmpdir, mychart.Name())
+
+	linter := support.Linter{ChartDir: chartDir}
+	Templates(&linter, values, namespace, strict)
+	if len(linter.Messages) != 1 || !strings.Contains(linter.Messages[0].Err.Error(), `sets metadata.namespace to "kube-system"`) {
+		t.Errorf("Expected the default Kubernetes version to render the legacy manifest, got %v", linter.Messages)
+	}
+
+	kubeVersion, err := chartutil.ParseKubeVersion("1.21")
+	if err != nil {
+		t.Fatal(err)
+	}
+	linter = support.Linter{ChartDir: chartDir, KubeVersion: kubeVersion}
+	Templates(&linter, values, namespace, strict)
+	if len(linter.Messages) != 0 {
+		t.Errorf("Expected no lint messages for Kubernetes 1.21, got %v", linter.Messages)
+	}
+	if linter.Capabilities.KubeVersion.Version != "v1.21.0" {
+		t.Errorf("Expected the capabilities to use Kubernetes v1.21.0, got %s", linter.Capabilities.KubeVersion.Version)
+	}
+	if chartutil.DefaultCapabilities.KubeVersion.Version == "v1.21.0" {
+		t.Error("Expected the default capabilities to be left unchanged")
+	}
+}
+
+func TestValidateNoMissingValues(t *testing.T) {
+	vals := chartutil.Values{
+		"image": map[string]interface{}{
+			"repository": "nginx",
+			"tag":        nil,
+		},
+		"replicas": 1,
+		"labels":   "app=nginx",
+	}
+	tests := []struct {
+		name     string
+		template string
+		missing  string
+	}{
+		{"set", `image: {{ .Values.image.repository }}:{{ .Values.image.tag }}`, ""},
+		{"missing", `port: {{ .Values.service.port }}`, ".Values.service.port"},
+		{"missing in root scope", `{{ with .Chart }}port: {{ $.Values.service.port }}{{ end }}`, ".Values.service.port"},
+		{"missing below an if on other data", `{{ if .Release.IsInstall }}port: {{ .Values.service.port }}{{ end }}`, ".Values.service.port"},
+		{"defaulted", `port: {{ .Values.service.port | default 80 }}`, ""},
+		{"defaulted in parentheses", `port: {{ quote (default 80 .Values.service.port) }}`, ""},
+		{"assigned to a variable", `{{ $port := .Values.service.port }}`, ""},
+		{"all values", `{{ toYaml .Values }}`, ""},
+		{"guarded by if", `{{ if .Values.service }}port: {{ .Values.service.port }}{{ end }}`, ""},
+		{"guarded by another value", `{{ if .Values.ingress.enabled }}hosts: {{ .Values.ingress.hosts }}{{ end }}`, ""},
+		{"guarded by with", `{{ with .Values.service }}port: {{ $.Values.service.port }}{{ end }}`, ""},
+		{"guarded by range", `{{ range .Values.image }}port: {{ $.Values.service.port }}{{ end }}`, ""},
+		{"else of a guard", `{{ if .Values.image }}{{ else }}port: {{ .Values.service.port }}{{ end }}`, ""},
+		{"rebound dot", `{{ range .Chart.Keywords }}{{ .Values.service.port }}{{ end }}`, ""},
+		{"not a map", `{{ .Values.labels.app }}`, ""},
+		{"named template", `{{ define "port" }}{{ .Values.service.port }}{{ end }}`, ""},
+		{"reported once", `{{ .Values.service.port }}{{ .Values.service.port }}{{ .Values.debug }}`, ".Values.service.port, .Values.debug"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateNoMissingValues("templates/test.yaml", []byte(tt.template), vals)
+			if tt.missing == "" {
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+				return
+			}
+			expected := "values are referenced but not set: " + tt.missing + ", set them in values.yaml or use default"
+			if err == nil || err.Error() != expected {
+				t.Errorf("Expected %q, got %v", expected, err)
+			}
+		})
+	}
+}
+
+func TestTemplatesMissingValues(t *testing.T) {
+	configMap := []byte(`apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: {{ .Release.Name }}
+data:
+  name: {{ .Values.nameOverride | default .Chart.Name }}
+  port: {{ .Values.port | quote }}
+`)
+	mychart := chart.Chart{
+		Metadata: &chart.Metadata{
+			APIVersion: "v2",
+			Name:       "missingvalues",
+			Version:    "0.1.0",
+			Icon:       "satisfy-the-linting-gods.gif",
+		},
+		Templates: []*chart.File{
+			{Name: "templates/configmap.yaml", Data: configMap},
+		},
+	}
+	tmpdir := ensure.TempDir(t)
+	defer os.RemoveAll(tmpdir)
+
+	if err := chartutil.SaveDir(&mychart, tmpdir); err != nil {
+		t.Fatal(err)
+	}
+
+	linter := support.Linter{ChartDir: filepath.Join(tmpdir, mychart.Name())}
+	Templates(&linter, values, namespace, strict)
+	if l := len(linter.Messages); l != 1 {
+		for i, msg := range linter.Messages {
+			t.Logf("Message %d: %s", i, msg)
+		}
+		t.Fatalf("Expected 1 lint warning, got %d", l)
+	}
+	msg := linter.Messages[0]
+	if msg.Severity != support.WarningSev {
+		t.Errorf("Expected a warning, got severity %d", msg.Severity)
+	}
+	if !strings.Contains(msg.Err.Error(), "not set: .Values.port,") {
+		t.Errorf("Expected .Values.port to be reported, got %q", msg.Err)
+	}
+}
+
+func TestValidateHookDeletePolicy(t *testing.T) {
+	tests := []struct {
+		name     string
+		policies map[string]string
+		expected string
+	}{
+		{"no policy", nil, ""},
+		{"known policies", map[string]string{release.HookDeleteAnnotation: "before-hook-creation, Hook-Succeeded"}, ""},
+		{"misspelled policy", map[string]string{release.HookDeleteAnnotation: "hook-succeded,hook-failed"},
+			`Job "migrate" has unknown helm.sh/hook-delete-policy "hook-succeded", use before-hook-creation, hook-succeeded or hook-failed`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			obj := &K8sYamlStruct{
+				APIVersion: "batch/v1",
+				Kind:       "Job",
+				Metadata:   k8sYamlMetadata{Name: "migrate", Annotations: tt.policies},
+			}
+			err := validateHookDeletePolicy(obj)
+			if tt.expected == "" {
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.expected {
+				t.Errorf("Expected %q, got %v", tt.expected, err)
+			}
+		})
+	}
+}
+
+func TestValidateOverridesUsed(t *testing.T) {
+	deployment := []byte(`apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: {{ .Values.nameOverride | default .Release.Name }}
+spec:
+  replicas: {{ .Values.replicaCount }}
+  template:
+    spec:
+      containers:
+        - name: web
+          resources:
+            {{- toYaml .Values.resources | nindent 12 }}
+`)
+	mychart := chart.Chart{
+		Metadata: &chart.Metadata{
+			APIVersion: "v2",
+			Name:       "overrides",
+			Version:    "0.1.0",
+			Icon:       "satisfy-the-linting-gods.gif",
+			Dependencies: []*chart.Dependency{
+				{Name: "cache", Version: "0.1.0", Condition: "cache.enabled"},
+			},
+		},
+		Templates: []*chart.File{{Name: "templates/deployment.yaml", Data: deployment}},
+		Values:    map[string]interface{}{"replicaCount": 1, "image": map[string]interface{}{"tag": "1.0"}},
+	}
+	mychart.AddDependency(&chart.Chart{
+		Metadata: &chart.Metadata{APIVersion: "v2", Name: "cache", Version: "0.1.0"},
+		Values:   map[string]interface{}{"size": "1Gi"},
+	})
+
+	tests := []struct {
+		name      string
+		overrides map[string]interface{}
+		unused    string
+	}{
+		{"defaults", map[string]interface{}{"replicaCount": 2, "image": map[string]interface{}{"tag": "2.0"}}, ""},
+		{"referenced", map[string]interface{}{"nameOverride": "web", "resources": map[string]interface{}{"limits": map[string]interface{}{"cpu": 1}}}, ""},
+		{"sub-chart", map[string]interface{}{"cache": map[string]interface{}{"enabled": false, "size": "2Gi"}}, ""},
+		{"global", map[string]interface{}{"global": map[string]interface{}{"imageRegistry": "example.com"}}, ""},
+		{"misspelled", map[string]interface{}{"replicaCont": 2, "image": map[string]interface{}{"tga": "2.0"}}, ".Values.image.tga, .Values.replicaCont"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateOverridesUsed(&mychart, tt.overrides)
+			if tt.unused == "" {
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+				return
+			}
+			expected := "overrides have no effect as the chart does not use them: " + tt.unused
+			if err == nil || err.Error() != expected {
+				t.Errorf("Expected %q, got %v", expected, err)
+			}
+		})
+	}
+
+	tmpdir := ensure.TempDir(t)
+	defer os.RemoveAll(tmpdir)
+	if err := chartutil.SaveDir(&mychart, tmpdir); err != nil {
+		t.Fatal(err)
+	}
+	overrides := map[string]interface{}{"replicaCont": 2}
+	linter := support.Linter{ChartDir: filepath.Join(tmpdir, mychart.Name())}
+	Templates(&linter, overrides, namespace, strict)
+	for _, msg := range linter.Messages {
+		if strings.Contains(msg.Err.Error(), "overrides have no effect") {
+			t.Errorf("Expected overrides not to be checked by default, got %s", msg)
+		}
+	}
+
+	linter = support.Linter{ChartDir: filepath.Join(tmpdir, mychart.Name()), CheckOverrides: true}
+	Templates(&linter, overrides, namespace, strict)
+	var found bool
+	for _, msg := range linter.Messages {
+		if msg.Severity == support.WarningSev && strings.Contains(msg.Err.Error(), "does not use them: .Values.replicaCont") {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("Expected a warning for .Values.replicaCont, got %v", linter.Messages)
+	}
+}
+
+func TestTemplatesNotes(t *testing.T) {
+	configMap := []byte(`apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: {{ .Release.Name }}
+data:
+  name: {{ .Chart.Name }}
+`)
+	tests := []struct {
+		name     string
+		notes    string
+		severity int
+		message  string
+	}{
+		{name: "usable", notes: `Installed {{ .Release.Name }}.`},
+		{name: "template error", notes: `{{ .Release.Name | nosuchfunc }}`, severity: support.ErrorSev, message: "templates/NOTES.txt"},
+		{name: "empty", notes: `{{ if .Values.showNotes }}Installed.{{ end }}`, severity: support.WarningSev, message: "renders to nothing"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mychart := chart.Chart{
+				Metadata: &chart.Metadata{
+					APIVersion: "v2",
+					Name:       "notes",
+					Version:    "0.1.0",
+					Icon:       "satisfy-the-linting-gods.gif",
+				},
+				Templates: []*chart.File{
+					{Name: "templates/configmap.yaml", Data: configMap},
+					{Name: "templates/NOTES.txt", Data: []byte(tt.notes)},
+				},
+			}
+			tmpdir := ensure.TempDir(t)
+			defer os.RemoveAll(tmpdir)
+
+			if err := chartutil.SaveDir(&mychart, tmpdir); err != nil {
+				t.Fatal(err)
+			}
+
+			linter := support.Linter{ChartDir: filepath.Join(tmpdir, mychart.Name())}
+			Templates(&linter, values, namespace, strict)
+			if tt.message == "" {
+				if len(linter.Messages) != 0 {
+					t.Fatalf("Expected no lint messages, got %v", linter.Messages)
+				}
+				return
+			}
+			if len(linter.Messages) != 1 {
+				t.Fatalf("Expected 1 lint message, got %v", linter.Messages)
+			}
+			msg := linter.Messages[0]
+			if msg.Severity != tt.severity || msg.Path != "templates/NOTES.txt" {
+				t.Errorf("Expected severity %d for templates/NOTES.txt, got %d for %s", tt.severity, msg.Severity, msg.Path)
+			}
+			if !strings.Contains(msg.Err.Error(), tt.message) {
+				t.Errorf("Expected %q in %q", tt.message, msg.Err)
+			}
+		})
+	}
+}
@@ -16,7 +16,11 @@ limitations under the License.
 
 package support
 
-import "fmt"
+import (
+	"fmt"
+
+	"helm.sh/helm/v3/pkg/chartutil"
+)
 
 // Severity indicates the severity of a Message.
 const (
@@ -39,6 +43,18 @@ type Linter struct {
 	// The highest severity of all the failing lint rules
 	HighestSeverity int
 	ChartDir        string
+	// ReleaseName is the release name the templates are rendered with
+	ReleaseName string
+	// KubeVersion is the Kubernetes version the templates are rendered with,
+	// the version of chartutil.DefaultCapabilities when nil
+	KubeVersion *chartutil.KubeVersion
+	// Capabilities are the capabilities the templates are rendered with,
+	// chartutil.DefaultCapabilities when nil. After linting they are the
+	// capabilities the templates were rendered with, KubeVersion applied.
+	Capabilities *chartutil.Capabilities
+	// CheckOverrides warns about values given to the linter that the chart
+	// does not use
+	CheckOverrides bool
 }
 
 // Message describes an error encountered while linting.
@@ -0,0 +1,132 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package postrender
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/yaml"
+)
+
+type annotator struct {
+	annotations map[string]string
+	labels      map[string]string
+}
+
+// NewAnnotator returns a PostRenderer that adds the given annotations and labels
+// to the metadata of every resource, including the items of List kinds.
+//
+// Existing annotations and labels are kept, unless they have the same key as
+// one being added. Comments in the manifests are not preserved.
+func NewAnnotator(annotations, labels map[string]string) PostRenderer {
+	return &annotator{annotations: annotations, labels: labels}
+}
+
+// Run adds the annotations and labels to each of the rendered manifests
+func (a *annotator) Run(renderedManifests *bytes.Buffer) (*bytes.Buffer, error) {
+	return mapDocuments(renderedManifests, func(obj map[string]interface{}) error {
+		if items, ok := obj["items"].([]interface{}); ok && strings.HasSuffix(kindOf(obj), "List") {
+			for _, item := range items {
+				if itemObj, ok := item.(map[string]interface{}); ok {
+					a.annotate(itemObj)
+				}
+			}
+			return nil
+		}
+		a.annotate(obj)
+		return nil
+	})
+}
+
+func (a *annotator) annotate(obj map[string]interface{}) {
+	metadata, ok := obj["metadata"].(map[string]interface{})
+	if !ok {
+		metadata = map[string]interface{}{}
+		obj["metadata"] = metadata
+	}
+	mergeStringMap(metadata, "annotations", a.annotations)
+	mergeStringMap(metadata, "labels", a.labels)
+}
+
+// mergeStringMap sets the entries of values in the map stored under key in
+// metadata, creating it if needed.
+func mergeStringMap(metadata map[string]interface{}, key string, values map[string]string) {
+	if len(values) == 0 {
+		return
+	}
+	existing, ok := metadata[key].(map[string]interface{})
+	if !ok {
+		existing = make(map[string]interface{}, len(values))
+		metadata[key] = existing
+	}
+	for k, v := range values {
+		existing[k] = v
+	}
+}
+
+func kindOf(obj map[string]interface{}) string {
+	kind, _ := obj["kind"].(string)
+	return kind
+}
+
+// mapDocuments calls fn on each YAML document of the manifests and returns the
+// modified documents. Empty documents are dropped.
+func mapDocuments(manifests *bytes.Buffer, fn func(obj map[string]interface{}) error) (*bytes.Buffer, error) {
+	out := &bytes.Buffer{}
+	for i, doc := range splitDocuments(manifests.String()) {
+		var obj map[string]interface{}
+		if err := yaml.Unmarshal([]byte(doc), &obj); err != nil {
+			return nil, errors.Wrapf(err, "unable to parse document %d of the rendered manifests", i)
+		}
+		if len(obj) == 0 {
+			continue
+		}
+		if err := fn(obj); err != nil {
+			return nil, err
+		}
+		data, err := yaml.Marshal(obj)
+		if err != nil {
+			return nil, err
+		}
+		out.WriteString("---\n")
+		out.Write(data)
+	}
+	return out, nil
+}
+
+// splitDocuments splits a YAML stream on its document separators, keeping the
+// order of the documents.
+func splitDocuments(manifests string) []string {
+	var docs []string
+	var doc strings.Builder
+	scanner := bufio.NewScanner(strings.NewReader(manifests))
+	scanner.Buffer(make([]byte, 0, 64*1024), len(manifests)+1)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.TrimRight(line, " \t") == "---" {
+			docs = append(docs, doc.String())
+			doc.Reset()
+			continue
+		}
+		doc.WriteString(line)
+		doc.WriteString("\n")
+	}
+	return append(docs, doc.String())
+}
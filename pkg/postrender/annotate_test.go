@@ -0,0 +1,100 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package postrender
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/yaml"
+)
+
+const annotatorManifests = `---
+# Source: mychart/templates/configmap.yaml
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config
+  labels:
+    app: mychart
+    team: original
+data:
+  key: value
+---
+# Source: mychart/templates/service.yaml
+apiVersion: v1
+kind: Service
+metadata:
+  name: svc
+spec:
+  ports:
+  - port: 80
+---
+---
+apiVersion: v1
+kind: ConfigMapList
+items:
+- apiVersion: v1
+  kind: ConfigMap
+  metadata:
+    name: listed
+`
+
+func TestAnnotator(t *testing.T) {
+	is := assert.New(t)
+
+	renderer := NewAnnotator(
+		map[string]string{"example.com/cost-center": "1234"},
+		map[string]string{"team": "platform"},
+	)
+	output, err := renderer.Run(bytes.NewBufferString(annotatorManifests))
+	require.NoError(t, err)
+
+	docs := splitDocuments(output.String())
+	require.Len(t, docs, 4, "expected the leading separator and three documents")
+	is.Empty(docs[0])
+
+	var configMap, service, list map[string]interface{}
+	require.NoError(t, yaml.Unmarshal([]byte(docs[1]), &configMap))
+	require.NoError(t, yaml.Unmarshal([]byte(docs[2]), &service))
+	require.NoError(t, yaml.Unmarshal([]byte(docs[3]), &list))
+
+	metadata := configMap["metadata"].(map[string]interface{})
+	is.Equal("config", metadata["name"])
+	is.Equal(map[string]interface{}{"app": "mychart", "team": "platform"}, metadata["labels"])
+	is.Equal(map[string]interface{}{"example.com/cost-center": "1234"}, metadata["annotations"])
+	is.Equal(map[string]interface{}{"key": "value"}, configMap["data"])
+
+	metadata = service["metadata"].(map[string]interface{})
+	is.Equal(map[string]interface{}{"team": "platform"}, metadata["labels"])
+	is.Equal(map[string]interface{}{"example.com/cost-center": "1234"}, metadata["annotations"])
+	is.NotNil(service["spec"])
+
+	is.Nil(list["metadata"], "the list itself should be left alone")
+	item := list["items"].([]interface{})[0].(map[string]interface{})
+	metadata = item["metadata"].(map[string]interface{})
+	is.Equal("listed", metadata["name"])
+	is.Equal(map[string]interface{}{"team": "platform"}, metadata["labels"])
+}
+
+func TestAnnotatorInvalidYAML(t *testing.T) {
+	renderer := NewAnnotator(nil, map[string]string{"team": "platform"})
+	_, err := renderer.Run(bytes.NewBufferString("kind: ConfigMap\n---\nkind: [unclosed\n"))
+	assert.Error(t, err)
+}
@@ -18,15 +18,31 @@ package postrender
 
 import (
 	"bytes"
+	"context"
 	"io"
+	"io/ioutil"
+	"os"
 	"os/exec"
+	"os/signal"
 	"path/filepath"
+	"sync"
+	"syscall"
+	"time"
 
+	"github.com/mattn/go-shellwords"
 	"github.com/pkg/errors"
 )
 
 type execRender struct {
 	binaryPath string
+	args       []string
+	env        []string
+	timeout    time.Duration
+	allowEmpty bool
+	// viaFile passes the manifests as the path of a temporary file, created
+	// in tempDir, instead of on stdin
+	viaFile bool
+	tempDir string
 }
 
 // NewExec returns a PostRenderer implementation that calls the provided binary.
@@ -38,34 +54,229 @@ func NewExec(binaryPath string) (PostRenderer, error) {
 	if err != nil {
 		return nil, err
 	}
-	return &execRender{fullPath}, nil
+	return &execRender{binaryPath: fullPath}, nil
 }
 
-// Run the configured binary for the post render
-func (p *execRender) Run(renderedManifests *bytes.Buffer) (*bytes.Buffer, error) {
-	cmd := exec.Command(p.binaryPath)
-	stdin, err := cmd.StdinPipe()
+// NewExecWithEnv returns a PostRenderer like NewExec that runs the binary with
+// the given "KEY=value" entries added to the environment of the Helm process.
+// Entries in env override variables of the same name.
+func NewExecWithEnv(binaryPath string, env []string) (PostRenderer, error) {
+	fullPath, err := getFullPath(binaryPath)
+	if err != nil {
+		return nil, err
+	}
+	return &execRender{binaryPath: fullPath, env: env}, nil
+}
+
+// NewExecWithTimeout returns a PostRenderer like NewExec that stops the binary,
+// along with any processes it started, if it has not finished within timeout.
+// The output of a binary that timed out is discarded.
+func NewExecWithTimeout(binaryPath string, timeout time.Duration) (PostRenderer, error) {
+	fullPath, err := getFullPath(binaryPath)
+	if err != nil {
+		return nil, err
+	}
+	return &execRender{binaryPath: fullPath, timeout: timeout}, nil
+}
+
+// NewExecAllowEmpty returns a PostRenderer like NewExec that accepts an empty
+// output from the binary, for post-renderers meant to remove every manifest.
+func NewExecAllowEmpty(binaryPath string) (PostRenderer, error) {
+	fullPath, err := getFullPath(binaryPath)
+	if err != nil {
+		return nil, err
+	}
+	return &execRender{binaryPath: fullPath, allowEmpty: true}, nil
+}
+
+// NewExecViaFile returns a PostRenderer like NewExec for binaries that read the
+// manifests from a file instead of stdin. The manifests are written to a
+// temporary file that only the current user can read, and its path is passed
+// as the last argument. The file is removed when the binary exits, if Run
+// panics, and if Helm is interrupted or terminated while the binary runs.
+func NewExecViaFile(binaryPath string) (PostRenderer, error) {
+	fullPath, err := getFullPath(binaryPath)
 	if err != nil {
 		return nil, err
 	}
+	return &execRender{binaryPath: fullPath, viaFile: true}, nil
+}
+
+type execChain struct {
+	commands []string
+	stages   []*execRender
+}
+
+// NewExecChain returns a PostRenderer implementation that runs the provided
+// commands as a pipeline, passing the output of each command to the next. Each
+// command is split into a binary and its arguments following shell quoting
+// rules, and the binary is resolved as it is by NewExec.
+func NewExecChain(commands []string) (PostRenderer, error) {
+	if len(commands) == 0 {
+		return nil, errors.New("no post-render commands given")
+	}
+	chain := &execChain{commands: commands}
+	for i, command := range commands {
+		words, err := shellwords.Parse(command)
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to parse post-render command %d (%s)", i, command)
+		}
+		if len(words) == 0 {
+			return nil, errors.Errorf("post-render command %d is empty", i)
+		}
+		fullPath, err := getFullPath(words[0])
+		if err != nil {
+			return nil, err
+		}
+		chain.stages = append(chain.stages, &execRender{binaryPath: fullPath, args: words[1:]})
+	}
+	return chain, nil
+}
+
+// Run each command of the chain in turn on the output of the previous one
+func (p *execChain) Run(renderedManifests *bytes.Buffer) (*bytes.Buffer, error) {
+	for i, stage := range p.stages {
+		var err error
+		renderedManifests, err = stage.Run(renderedManifests)
+		if err != nil {
+			return nil, errors.Wrapf(err, "post-render stage %d (%s) failed", i, p.commands[i])
+		}
+	}
+	return renderedManifests, nil
+}
+
+// Run the configured binary for the post render
+func (p *execRender) Run(renderedManifests *bytes.Buffer) (*bytes.Buffer, error) {
+	args := p.args
+	if p.viaFile {
+		manifestPath, cleanup, err := writeManifestFile(p.tempDir, renderedManifests)
+		if err != nil {
+			return nil, err
+		}
+		defer cleanup()
+		args = append(append([]string(nil), p.args...), manifestPath)
+	}
+
+	cmd := exec.Command(p.binaryPath, args...)
+	if len(p.env) > 0 {
+		cmd.Env = append(os.Environ(), p.env...)
+	}
 
 	var postRendered = &bytes.Buffer{}
 	var stderr = &bytes.Buffer{}
 	cmd.Stdout = postRendered
 	cmd.Stderr = stderr
 
-	go func() {
-		defer stdin.Close()
-		io.Copy(stdin, renderedManifests)
-	}()
-	err = cmd.Run()
+	if p.timeout > 0 {
+		setProcessGroup(cmd)
+	}
+
+	if !p.viaFile {
+		stdin, err := cmd.StdinPipe()
+		if err != nil {
+			return nil, err
+		}
+		go func() {
+			defer stdin.Close()
+			io.Copy(stdin, renderedManifests)
+		}()
+	}
+	err := p.run(cmd)
+	if err == context.DeadlineExceeded {
+		return nil, errors.Errorf("post-renderer timed out after %s", p.timeout)
+	}
 	if err != nil {
 		return nil, errors.Wrapf(err, "error while running command %s. error output:\n%s", p.binaryPath, stderr.String())
 	}
+	if !p.allowEmpty && len(bytes.TrimSpace(postRendered.Bytes())) == 0 {
+		return nil, errors.New("post-renderer returned an empty manifest")
+	}
 
 	return postRendered, nil
 }
 
+// writeManifestFile writes the manifests to a new temporary file in dir, or the
+// default directory for temporary files when dir is empty. The file is only
+// readable by the current user. The returned function removes it, which also
+// happens if Helm receives an interrupt or termination signal before then.
+func writeManifestFile(dir string, manifests *bytes.Buffer) (string, func(), error) {
+	f, err := ioutil.TempFile(dir, "helm-post-render-*.yaml")
+	if err != nil {
+		return "", nil, errors.Wrap(err, "unable to create post-render manifest file")
+	}
+	name := f.Name()
+
+	var once sync.Once
+	remove := func() {
+		once.Do(func() { os.Remove(name) })
+	}
+	signals := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		select {
+		case sig := <-signals:
+			remove()
+			signal.Stop(signals)
+			// Deliver the signal again now that it is no longer caught, so
+			// that Helm exits as it would have without the file.
+			if proc, err := os.FindProcess(os.Getpid()); err != nil || proc.Signal(sig) != nil {
+				os.Exit(1)
+			}
+		case <-done:
+		}
+	}()
+	cleanup := func() {
+		signal.Stop(signals)
+		close(done)
+		remove()
+	}
+
+	if err := f.Chmod(0600); err != nil {
+		f.Close()
+		cleanup()
+		return "", nil, errors.Wrap(err, "unable to restrict permissions of post-render manifest file")
+	}
+	if _, err := io.Copy(f, manifests); err != nil {
+		f.Close()
+		cleanup()
+		return "", nil, errors.Wrap(err, "unable to write post-render manifest file")
+	}
+	if err := f.Close(); err != nil {
+		cleanup()
+		return "", nil, errors.Wrap(err, "unable to write post-render manifest file")
+	}
+	return name, cleanup, nil
+}
+
+// run runs the command, killing its process group and returning
+// context.DeadlineExceeded if it outlives the timeout of the post-renderer.
+func (p *execRender) run(cmd *exec.Cmd) error {
+	if p.timeout <= 0 {
+		return cmd.Run()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), p.timeout)
+	defer cancel()
+
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- cmd.Wait()
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		killProcessGroup(cmd)
+		<-done
+		return ctx.Err()
+	}
+}
+
 // getFullPath returns the full filepath to the binary to execute. If the path
 // does not contain any separators, it will search in $PATH, otherwise it will
 // resolve any relative paths to a fully qualified path
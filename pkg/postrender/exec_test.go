@@ -23,6 +23,7 @@ import (
 	"path/filepath"
 	"runtime"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -124,8 +125,161 @@ func TestExecRun(t *testing.T) {
 	is.Contains(output.String(), "BARTEST")
 }
 
+func TestExecWithEnvRun(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		// the script is a shell script, so skip this test on windows
+		t.Skip("skipping on windows")
+	}
+	is := assert.New(t)
+	testpath, cleanup := setupTestingScriptContent(t, "#!/bin/sh\necho \"release: $RELEASE, home: $HOME\"\n")
+	defer cleanup()
+
+	renderer, err := NewExecWithEnv(testpath, []string{"RELEASE=foo", "HOME=/overridden"})
+	require.NoError(t, err)
+
+	output, err := renderer.Run(bytes.NewBufferString("FOOTEST"))
+	is.NoError(err)
+	is.Equal("release: foo, home: /overridden\n", output.String())
+}
+
+func TestExecWithTimeoutRun(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		// the script is a shell script, so skip this test on windows
+		t.Skip("skipping on windows")
+	}
+	is := assert.New(t)
+	testpath, cleanup := setupTestingScriptContent(t, "#!/bin/sh\necho partial\nsleep 30\n")
+	defer cleanup()
+
+	renderer, err := NewExecWithTimeout(testpath, 100*time.Millisecond)
+	require.NoError(t, err)
+
+	start := time.Now()
+	output, err := renderer.Run(bytes.NewBufferString("FOOTEST"))
+	is.EqualError(err, "post-renderer timed out after 100ms")
+	is.Nil(output)
+	is.Less(int64(time.Since(start)), int64(10*time.Second), "the sleeping child process was not killed")
+
+	fastpath, fastCleanup := setupTestingScript(t)
+	defer fastCleanup()
+
+	renderer, err = NewExecWithTimeout(fastpath, 10*time.Second)
+	require.NoError(t, err)
+	output, err = renderer.Run(bytes.NewBufferString("FOOTEST"))
+	is.NoError(err)
+	is.Contains(output.String(), "BARTEST")
+}
+
+func TestExecRunEmptyOutput(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		// the script is a shell script, so skip this test on windows
+		t.Skip("skipping on windows")
+	}
+	is := assert.New(t)
+	testpath, cleanup := setupTestingScriptContent(t, "#!/bin/sh\ncat > /dev/null\necho\n")
+	defer cleanup()
+
+	renderer, err := NewExec(testpath)
+	require.NoError(t, err)
+	output, err := renderer.Run(bytes.NewBufferString("FOOTEST"))
+	is.EqualError(err, "post-renderer returned an empty manifest")
+	is.Nil(output)
+
+	renderer, err = NewExecAllowEmpty(testpath)
+	require.NoError(t, err)
+	output, err = renderer.Run(bytes.NewBufferString("FOOTEST"))
+	is.NoError(err)
+	is.Equal("\n", output.String())
+}
+
+func TestExecViaFileRun(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		// the script is a shell script, so skip this test on windows
+		t.Skip("skipping on windows")
+	}
+	is := assert.New(t)
+	tests := []struct {
+		name   string
+		script string
+		err    string
+	}{
+		{name: "success", script: "#!/bin/sh\nls -l \"$1\" | cut -c1-10\nsed s/FOOTEST/BARTEST/g \"$1\"\n"},
+		{name: "failure", script: "#!/bin/sh\ncat \"$1\" > /dev/null\nexit 3\n", err: "exit status 3"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testpath, cleanup := setupTestingScriptContent(t, tt.script)
+			defer cleanup()
+			tempDir := ensure.TempDir(t)
+			defer os.RemoveAll(tempDir)
+
+			renderer, err := NewExecViaFile(testpath)
+			require.NoError(t, err)
+			renderer.(*execRender).tempDir = tempDir
+
+			output, err := renderer.Run(bytes.NewBufferString("FOOTEST"))
+			if tt.err != "" {
+				require.Error(t, err)
+				is.Contains(err.Error(), tt.err)
+			} else {
+				require.NoError(t, err)
+				is.Equal("-rw-------\nBARTEST", output.String())
+			}
+
+			files, err := ioutil.ReadDir(tempDir)
+			require.NoError(t, err)
+			is.Empty(files, "the manifest file was not removed")
+		})
+	}
+}
+
+func TestExecChainRun(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		// the chain runs sed, so skip this test on windows
+		t.Skip("skipping on windows")
+	}
+	is := assert.New(t)
+
+	renderer, err := NewExecChain([]string{
+		"sed s/FOOTEST/BARTEST/g",
+		"sed 's/BARTEST/BAZ TEST/g'",
+	})
+	require.NoError(t, err)
+
+	output, err := renderer.Run(bytes.NewBufferString("FOOTEST\n"))
+	is.NoError(err)
+	is.Equal("BAZ TEST\n", output.String())
+}
+
+func TestExecChainErrors(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("skipping on windows")
+	}
+	is := assert.New(t)
+
+	_, err := NewExecChain(nil)
+	is.Error(err)
+
+	_, err = NewExecChain([]string{"sed s/a/b/g", " "})
+	is.EqualError(err, "post-render command 1 is empty")
+
+	_, err = NewExecChain([]string{"sed 's/a/b/g"})
+	is.Error(err)
+
+	renderer, err := NewExecChain([]string{"sed s/a/b/g", "sh -c 'exit 3'"})
+	require.NoError(t, err)
+	_, err = renderer.Run(bytes.NewBufferString("a"))
+	require.Error(t, err)
+	is.Contains(err.Error(), "post-render stage 1 (sh -c 'exit 3') failed")
+}
+
 func setupTestingScript(t *testing.T) (filepath string, cleanup func()) {
 	t.Helper()
+	return setupTestingScriptContent(t, testingScript)
+}
+
+func setupTestingScriptContent(t *testing.T, script string) (filepath string, cleanup func()) {
+	t.Helper()
 
 	tempdir := ensure.TempDir(t)
 
@@ -134,7 +288,7 @@ func setupTestingScript(t *testing.T) (filepath string, cleanup func()) {
 		t.Fatalf("unable to create tempfile for testing: %s", err)
 	}
 
-	_, err = f.WriteString(testingScript)
+	_, err = f.WriteString(script)
 	if err != nil {
 		t.Fatalf("unable to write tempfile for testing: %s", err)
 	}
@@ -0,0 +1,94 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package postrender
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+type fieldStripper struct {
+	paths [][]string
+}
+
+// NewFieldStripper returns a PostRenderer that removes the fields at the given
+// JSON pointers (RFC 6901), such as /metadata/namespace or /status, from every
+// resource, including the items of List kinds. Fields that are not present are
+// ignored. It returns an error if a path is not a valid JSON pointer.
+func NewFieldStripper(paths []string) (PostRenderer, error) {
+	s := &fieldStripper{}
+	for _, p := range paths {
+		if !strings.HasPrefix(p, "/") {
+			return nil, errors.Errorf("invalid field path %q: must be a JSON pointer starting with '/'", p)
+		}
+		tokens := strings.Split(p[1:], "/")
+		for i, token := range tokens {
+			tokens[i] = strings.NewReplacer("~1", "/", "~0", "~").Replace(token)
+		}
+		s.paths = append(s.paths, tokens)
+	}
+	return s, nil
+}
+
+// Run removes the configured fields from each of the rendered manifests
+func (s *fieldStripper) Run(renderedManifests *bytes.Buffer) (*bytes.Buffer, error) {
+	return mapDocuments(renderedManifests, func(obj map[string]interface{}) error {
+		if items, ok := obj["items"].([]interface{}); ok && strings.HasSuffix(kindOf(obj), "List") {
+			for _, item := range items {
+				if itemObj, ok := item.(map[string]interface{}); ok {
+					s.strip(itemObj)
+				}
+			}
+			return nil
+		}
+		s.strip(obj)
+		return nil
+	})
+}
+
+func (s *fieldStripper) strip(obj map[string]interface{}) {
+	for _, path := range s.paths {
+		removeField(obj, path)
+	}
+}
+
+// removeField removes the field at path from value and returns the value,
+// which only changes when an item is removed from a list.
+func removeField(value interface{}, path []string) interface{} {
+	key, rest := path[0], path[1:]
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if len(rest) == 0 {
+			delete(v, key)
+		} else if child, ok := v[key]; ok {
+			v[key] = removeField(child, rest)
+		}
+	case []interface{}:
+		i, err := strconv.Atoi(key)
+		if err != nil || i < 0 || i >= len(v) {
+			return v
+		}
+		if len(rest) == 0 {
+			return append(v[:i:i], v[i+1:]...)
+		}
+		v[i] = removeField(v[i], rest)
+	}
+	return value
+}
@@ -0,0 +1,92 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package postrender
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/yaml"
+)
+
+const stripperManifests = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: default
+  annotations:
+    example.com/owner: team
+spec:
+  template:
+    spec:
+      containers:
+      - name: web
+        image: nginx
+      - name: sidecar
+        image: envoy
+status:
+  replicas: 1
+---
+apiVersion: v1
+kind: List
+items:
+- apiVersion: v1
+  kind: Service
+  metadata:
+    name: svc
+    namespace: default
+`
+
+func TestFieldStripper(t *testing.T) {
+	is := assert.New(t)
+
+	renderer, err := NewFieldStripper([]string{
+		"/metadata/namespace",
+		"/status",
+		"/metadata/annotations/example.com~1owner",
+		"/spec/template/spec/containers/1",
+		"/does/not/exist",
+	})
+	require.NoError(t, err)
+
+	output, err := renderer.Run(bytes.NewBufferString(stripperManifests))
+	require.NoError(t, err)
+
+	docs := splitDocuments(output.String())
+	require.Len(t, docs, 3)
+
+	var deployment, list map[string]interface{}
+	require.NoError(t, yaml.Unmarshal([]byte(docs[1]), &deployment))
+	require.NoError(t, yaml.Unmarshal([]byte(docs[2]), &list))
+
+	is.NotContains(deployment, "status")
+	metadata := deployment["metadata"].(map[string]interface{})
+	is.Equal(map[string]interface{}{"name": "web", "annotations": map[string]interface{}{}}, metadata)
+	containers := deployment["spec"].(map[string]interface{})["template"].(map[string]interface{})["spec"].(map[string]interface{})["containers"].([]interface{})
+	is.Equal([]interface{}{map[string]interface{}{"name": "web", "image": "nginx"}}, containers)
+
+	item := list["items"].([]interface{})[0].(map[string]interface{})
+	is.Equal(map[string]interface{}{"name": "svc"}, item["metadata"])
+	is.Equal("Service", item["kind"])
+}
+
+func TestFieldStripperInvalidPath(t *testing.T) {
+	_, err := NewFieldStripper([]string{"metadata/namespace"})
+	assert.EqualError(t, err, `invalid field path "metadata/namespace": must be a JSON pointer starting with '/'`)
+}
@@ -49,6 +49,9 @@ type Entry struct {
 	CAFile                string `json:"caFile"`
 	InsecureSkipTLSverify bool   `json:"insecure_skip_tls_verify"`
 	PassCredentialsAll    bool   `json:"pass_credentials_all"`
+	// Headers are extra HTTP headers sent when fetching from the repository,
+	// for artifact managers that authenticate with non-standard headers.
+	Headers map[string]string `json:"headers,omitempty"`
 }
 
 // ChartRepository represents a chart repository
@@ -116,10 +119,38 @@ func (r *ChartRepository) Load() error {
 
 // DownloadIndexFile fetches the index from a repository.
 func (r *ChartRepository) DownloadIndexFile() (string, error) {
-	parsedURL, err := url.Parse(r.Config.URL)
+	index, indexFile, err := r.fetchIndex()
 	if err != nil {
 		return "", err
 	}
+
+	// Create the chart list file in the cache directory
+	var charts strings.Builder
+	for name := range indexFile.Entries {
+		fmt.Fprintln(&charts, name)
+	}
+	chartsFile := filepath.Join(r.CachePath, helmpath.CacheChartsFile(r.Config.Name))
+	os.MkdirAll(filepath.Dir(chartsFile), 0755)
+	ioutil.WriteFile(chartsFile, []byte(charts.String()), 0644)
+
+	// Create the index file in the cache directory
+	fname := filepath.Join(r.CachePath, helmpath.CacheIndexFile(r.Config.Name))
+	os.MkdirAll(filepath.Dir(fname), 0755)
+	return fname, ioutil.WriteFile(fname, index, 0644)
+}
+
+// FetchIndexFile fetches and parses the index file of the repository using its
+// configured credentials, without writing anything to the cache.
+func (r *ChartRepository) FetchIndexFile() (*IndexFile, error) {
+	_, indexFile, err := r.fetchIndex()
+	return indexFile, err
+}
+
+func (r *ChartRepository) fetchIndex() ([]byte, *IndexFile, error) {
+	parsedURL, err := url.Parse(r.Config.URL)
+	if err != nil {
+		return nil, nil, err
+	}
 	parsedURL.RawPath = path.Join(parsedURL.RawPath, "index.yaml")
 	parsedURL.Path = path.Join(parsedURL.Path, "index.yaml")
 
@@ -131,34 +162,22 @@ func (r *ChartRepository) DownloadIndexFile() (string, error) {
 		getter.WithTLSClientConfig(r.Config.CertFile, r.Config.KeyFile, r.Config.CAFile),
 		getter.WithBasicAuth(r.Config.Username, r.Config.Password),
 		getter.WithPassCredentialsAll(r.Config.PassCredentialsAll),
+		getter.WithHeaders(r.Config.Headers),
 	)
 	if err != nil {
-		return "", err
+		return nil, nil, err
 	}
 
 	index, err := ioutil.ReadAll(resp)
 	if err != nil {
-		return "", err
+		return nil, nil, err
 	}
 
 	indexFile, err := loadIndex(index, r.Config.URL)
 	if err != nil {
-		return "", err
+		return nil, nil, err
 	}
-
-	// Create the chart list file in the cache directory
-	var charts strings.Builder
-	for name := range indexFile.Entries {
-		fmt.Fprintln(&charts, name)
-	}
-	chartsFile := filepath.Join(r.CachePath, helmpath.CacheChartsFile(r.Config.Name))
-	os.MkdirAll(filepath.Dir(chartsFile), 0755)
-	ioutil.WriteFile(chartsFile, []byte(charts.String()), 0644)
-
-	// Create the index file in the cache directory
-	fname := filepath.Join(r.CachePath, helmpath.CacheIndexFile(r.Config.Name))
-	os.MkdirAll(filepath.Dir(fname), 0755)
-	return fname, ioutil.WriteFile(fname, index, 0644)
+	return index, indexFile, nil
 }
 
 // Index generates an index for the chart repository and writes an index.yaml file.
@@ -246,6 +246,58 @@ func (i *IndexFile) Merge(f *IndexFile) {
 	}
 }
 
+// MergeConflictPolicy decides which entry MergeIndexes keeps when indexes have
+// different entries, by digest, for the same chart name and version.
+type MergeConflictPolicy int
+
+const (
+	// PreferFirst keeps the entry of the first index that has it.
+	PreferFirst MergeConflictPolicy = iota
+	// PreferLast keeps the entry of the last index that has it.
+	PreferLast
+)
+
+// MergeIndexes merges index files into a new, sorted index, such as to build
+// a repository aggregating several others.
+//
+// Entries with the same name, version and digest are kept once, from the
+// first index that has them. Entries with the same name and version but a
+// different digest conflict, and policy decides which one is kept. Nil
+// indexes are skipped.
+func MergeIndexes(policy MergeConflictPolicy, indexes ...*IndexFile) *IndexFile {
+	merged := NewIndexFile()
+	for _, f := range indexes {
+		if f == nil {
+			continue
+		}
+		for name, cvs := range f.Entries {
+			for _, cv := range cvs {
+				versions := merged.Entries[name]
+				j := versions.indexOf(cv.Version)
+				switch {
+				case j < 0:
+					merged.Entries[name] = append(versions, cv)
+				case versions[j].Digest != cv.Digest && policy == PreferLast:
+					versions[j] = cv
+				}
+			}
+		}
+	}
+	merged.SortEntries()
+	return merged
+}
+
+// indexOf returns the position of the entry with exactly the given version,
+// or -1 if there is none.
+func (c ChartVersions) indexOf(version string) int {
+	for i, cv := range c {
+		if cv.Version == version {
+			return i
+		}
+	}
+	return -1
+}
+
 // ChartVersion represents a chart entry in the IndexFile
 type ChartVersion struct {
 	*chart.Metadata
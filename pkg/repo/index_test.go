@@ -23,6 +23,7 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"reflect"
 	"sort"
 	"strings"
 	"testing"
@@ -214,6 +215,65 @@ func TestMerge(t *testing.T) {
 
 }
 
+func TestMergeIndexes(t *testing.T) {
+	mustAdd := func(i *IndexFile, name, version, digest string) {
+		t.Helper()
+		md := &chart.Metadata{APIVersion: "v2", Name: name, Version: version}
+		if err := i.MustAdd(md, name+"-"+version+".tgz", "http://example.com", digest); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	}
+
+	first := NewIndexFile()
+	mustAdd(first, "dreadnought", "0.1.0", "aaaa")
+	mustAdd(first, "dreadnought", "0.2.0", "bbbb")
+	mustAdd(first, "doughnut", "0.1.0", "cccc")
+
+	second := NewIndexFile()
+	// The same entry as in first
+	mustAdd(second, "dreadnought", "0.1.0", "aaaa")
+	// A conflicting entry for a version in first
+	mustAdd(second, "dreadnought", "0.2.0", "dddd")
+	mustAdd(second, "dreadnought", "0.3.0", "eeee")
+	mustAdd(second, "frigate", "1.0.0", "ffff")
+
+	digests := func(i *IndexFile, name string) []string {
+		var d []string
+		for _, cv := range i.Entries[name] {
+			d = append(d, cv.Version+"="+cv.Digest)
+		}
+		return d
+	}
+
+	tests := []struct {
+		policy      MergeConflictPolicy
+		dreadnought []string
+	}{
+		{PreferFirst, []string{"0.3.0=eeee", "0.2.0=bbbb", "0.1.0=aaaa"}},
+		{PreferLast, []string{"0.3.0=eeee", "0.2.0=dddd", "0.1.0=aaaa"}},
+	}
+	for _, tt := range tests {
+		merged := MergeIndexes(tt.policy, first, nil, second)
+		if len(merged.Entries) != 3 {
+			t.Errorf("policy %d: expected 3 charts, got %d", tt.policy, len(merged.Entries))
+		}
+		if got := digests(merged, "dreadnought"); !reflect.DeepEqual(got, tt.dreadnought) {
+			t.Errorf("policy %d: expected dreadnought versions %v, got %v", tt.policy, tt.dreadnought, got)
+		}
+		if got := digests(merged, "doughnut"); !reflect.DeepEqual(got, []string{"0.1.0=cccc"}) {
+			t.Errorf("policy %d: expected doughnut 0.1.0 from the first index, got %v", tt.policy, got)
+		}
+		if got := digests(merged, "frigate"); !reflect.DeepEqual(got, []string{"1.0.0=ffff"}) {
+			t.Errorf("policy %d: expected frigate 1.0.0 from the second index, got %v", tt.policy, got)
+		}
+	}
+
+	// The merged index has its own version lists
+	if len(first.Entries["dreadnought"]) != 2 {
+		t.Errorf("expected the first index to be left unchanged, got %v", digests(first, "dreadnought"))
+	}
+}
+
 func TestDownloadIndexFile(t *testing.T) {
 	t.Run("should  download index file", func(t *testing.T) {
 		srv, err := startLocalServerForTests(nil)
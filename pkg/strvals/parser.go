@@ -17,6 +17,7 @@ package strvals
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"io"
 	"strconv"
@@ -103,6 +104,18 @@ func ParseIntoFile(s string, dest map[string]interface{}, reader RunesValueReade
 	return t.parse()
 }
 
+// ParseJSON parses a set line whose values are JSON documents and merges the
+// result into dest.
+//
+// A set line is of the form name1=jsonval1,name2=jsonval2, for example
+// image={"repository":"nginx","tag":"1.21"},ports=[80,443]. An empty value
+// sets the key to null.
+func ParseJSON(s string, dest map[string]interface{}) error {
+	scanner := bytes.NewBufferString(s)
+	t := newJSONParser(scanner, dest)
+	return t.parse()
+}
+
 // RunesValueReader is a function that takes the given value (a slice of runes)
 // and returns the parsed value
 type RunesValueReader func([]rune) (interface{}, error)
@@ -113,9 +126,10 @@ type RunesValueReader func([]rune) (interface{}, error)
 // where sc is the source of the original data being parsed
 // where data is the final parsed data from the parses with correct types
 type parser struct {
-	sc     *bytes.Buffer
-	data   map[string]interface{}
-	reader RunesValueReader
+	sc        *bytes.Buffer
+	data      map[string]interface{}
+	reader    RunesValueReader
+	isjsonval bool
 }
 
 func newParser(sc *bytes.Buffer, data map[string]interface{}, stringBool bool) *parser {
@@ -129,6 +143,10 @@ func newFileParser(sc *bytes.Buffer, data map[string]interface{}, reader RunesVa
 	return &parser{sc: sc, data: data, reader: reader}
 }
 
+func newJSONParser(sc *bytes.Buffer, data map[string]interface{}) *parser {
+	return &parser{sc: sc, data: data, isjsonval: true}
+}
+
 func (t *parser) parse() error {
 	for {
 		err := t.key(t.data)
@@ -175,8 +193,10 @@ func (t *parser) key(data map[string]interface{}) (reterr error) {
 			kk := string(k)
 			// Find or create target list
 			list := []interface{}{}
-			if _, ok := data[kk]; ok {
-				list = data[kk].([]interface{})
+			if existing, ok := data[kk]; ok && existing != nil {
+				if list, ok = existing.([]interface{}); !ok {
+					return errors.Errorf("key %q is a %s, not a list", kk, typeName(existing))
+				}
 			}
 
 			// Now we need to get the value after the ].
@@ -184,6 +204,14 @@ func (t *parser) key(data map[string]interface{}) (reterr error) {
 			set(data, kk, list)
 			return err
 		case last == '=':
+			if t.isjsonval {
+				v, err := t.jsonVal()
+				if err != nil {
+					return errors.Wrapf(err, "key %q", string(k))
+				}
+				set(data, string(k), v)
+				return nil
+			}
 			//End of key. Consume =, Get value.
 			// FIXME: Get value list first
 			vl, e := t.valList()
@@ -213,8 +241,10 @@ func (t *parser) key(data map[string]interface{}) (reterr error) {
 		case last == '.':
 			// First, create or find the target map.
 			inner := map[string]interface{}{}
-			if _, ok := data[string(k)]; ok {
-				inner = data[string(k)].(map[string]interface{})
+			if existing, ok := data[string(k)]; ok && existing != nil {
+				if inner, ok = existing.(map[string]interface{}); !ok {
+					return errors.Errorf("key %q is a %s, not a map, so it cannot hold nested keys", string(k), typeName(existing))
+				}
 			}
 
 			// Recurse
@@ -280,6 +310,13 @@ func (t *parser) listItem(list []interface{}, i int) ([]interface{}, error) {
 	case err != nil:
 		return list, err
 	case last == '=':
+		if t.isjsonval {
+			v, err := t.jsonVal()
+			if err != nil {
+				return list, errors.Wrapf(err, "index %d", i)
+			}
+			return setIndex(list, i, v)
+		}
 		vl, e := t.valList()
 		switch e {
 		case nil:
@@ -343,6 +380,54 @@ func (t *parser) listItem(list []interface{}, i int) ([]interface{}, error) {
 	}
 }
 
+// jsonVal decodes the JSON value at the start of the remaining input and
+// consumes the comma separating it from the next key, if any.
+func (t *parser) jsonVal() (interface{}, error) {
+	if rest := strings.TrimSpace(t.sc.String()); rest == "" || rest[0] == ',' {
+		_, _, err := runesUntil(t.sc, runeSet([]rune{','}))
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+		return nil, nil
+	}
+
+	// The decoder reads ahead into its own buffer, so it is given a copy of the
+	// remaining input and only the bytes it actually decoded are consumed.
+	var v interface{}
+	dec := json.NewDecoder(strings.NewReader(t.sc.String()))
+	if err := dec.Decode(&v); err != nil {
+		return nil, errors.Wrap(err, "invalid JSON value")
+	}
+	t.sc.Next(int(dec.InputOffset()))
+
+	extra, _, err := runesUntil(t.sc, runeSet([]rune{','}))
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	if strings.TrimSpace(string(extra)) != "" {
+		return nil, errors.Errorf("unexpected data after JSON value: %q", string(extra))
+	}
+	return v, nil
+}
+
+// typeName describes the type of a parsed value in error messages.
+func typeName(v interface{}) string {
+	switch v.(type) {
+	case map[string]interface{}:
+		return "map"
+	case []interface{}:
+		return "list"
+	case string:
+		return "string"
+	case bool:
+		return "bool"
+	case int, int64, float64:
+		return "number"
+	default:
+		return fmt.Sprintf("%T", v)
+	}
+}
+
 func (t *parser) val() ([]rune, error) {
 	stop := runeSet([]rune{','})
 	v, _, err := runesUntil(t.sc, stop)
@@ -633,6 +633,86 @@ func TestParseIntoFile(t *testing.T) {
 	}
 }
 
+func TestParseJSON(t *testing.T) {
+	tests := []struct {
+		input  string
+		got    map[string]interface{}
+		expect map[string]interface{}
+		err    string
+	}{
+		{
+			input:  `outer.inner={"image":{"repository":"nginx","tag":"1.21"},"replicas":2}`,
+			got:    map[string]interface{}{},
+			expect: map[string]interface{}{"outer": map[string]interface{}{"inner": map[string]interface{}{"image": map[string]interface{}{"repository": "nginx", "tag": "1.21"}, "replicas": 2}}},
+		},
+		{
+			input:  `ports=[80,443],names=["a","b, c"],enabled=true`,
+			got:    map[string]interface{}{},
+			expect: map[string]interface{}{"ports": []interface{}{80, 443}, "names": []interface{}{"a", "b, c"}, "enabled": true},
+		},
+		{
+			input:  `list[1]={"name":"second"}`,
+			got:    map[string]interface{}{},
+			expect: map[string]interface{}{"list": []interface{}{nil, map[string]interface{}{"name": "second"}}},
+		},
+		{
+			input:  `outer.inner={"a":1},outer.other="x"`,
+			got:    map[string]interface{}{"outer": map[string]interface{}{"inner": map[string]interface{}{"b": 2}, "kept": true}},
+			expect: map[string]interface{}{"outer": map[string]interface{}{"inner": map[string]interface{}{"a": 1}, "kept": true, "other": "x"}},
+		},
+		{
+			input:  `empty=`,
+			got:    map[string]interface{}{},
+			expect: map[string]interface{}{"empty": nil},
+		},
+		{
+			input: `outer.inner=1`,
+			got:   map[string]interface{}{"outer": "a string"},
+			err:   `key "outer" is a string, not a map, so it cannot hold nested keys`,
+		},
+		{
+			input: `outer[0]=1`,
+			got:   map[string]interface{}{"outer": map[string]interface{}{}},
+			err:   `key "outer" is a map, not a list`,
+		},
+		{
+			input: `broken={"a":`,
+			got:   map[string]interface{}{},
+			err:   `key "broken": invalid JSON value: unexpected EOF`,
+		},
+		{
+			input: `trailing={"a":1}x`,
+			got:   map[string]interface{}{},
+			err:   `key "trailing": unexpected data after JSON value: "x"`,
+		},
+	}
+
+	for _, tt := range tests {
+		err := ParseJSON(tt.input, tt.got)
+		if tt.err != "" {
+			if err == nil || err.Error() != tt.err {
+				t.Errorf("%s: Expected error %q, got %v", tt.input, tt.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: %s", tt.input, err)
+		}
+
+		y1, err := yaml.Marshal(tt.expect)
+		if err != nil {
+			t.Fatal(err)
+		}
+		y2, err := yaml.Marshal(tt.got)
+		if err != nil {
+			t.Fatalf("Error serializing parsed value: %s", err)
+		}
+		if string(y1) != string(y2) {
+			t.Errorf("%s: Expected:\n%s\nGot:\n%s", tt.input, y1, y2)
+		}
+	}
+}
+
 func TestToYAML(t *testing.T) {
 	// The TestParse does the hard part. We just verify that YAML formatting is
 	// happening.